@@ -0,0 +1,152 @@
+package excel
+
+import (
+    "bytes"
+    "fmt"
+    "runtime"
+    "strconv"
+    "sync"
+    "sync/atomic"
+)
+
+//apartment is a goroutine locked to an OS thread which makes all the calls to the objects of an MSO,
+//as objects of a single-threaded COM apartment are only called on the thread creating them.
+//so an MSO may be used from any goroutine, and calls are done one by one.
+type apartment struct {
+    calls chan func()
+    done  chan struct{}
+    once  sync.Once
+    mutex sync.Mutex
+    refs  map[*aptRef]struct{}
+    seq   int64
+    busy  int32 //1 while loop runs a call, the only time a call may come from the apartment itself.
+    gid   int64 //id of the goroutine of loop.
+}
+
+//
+func newApartment() (*apartment) {
+    apt := &apartment{calls: make(chan func()), done: make(chan struct{}), refs: map[*aptRef]struct{}{}}
+    go apt.loop()
+    return apt
+}
+
+//
+func (apt *apartment) loop() {
+    runtime.LockOSThread()
+    defer runtime.UnlockOSThread()
+    atomic.StoreInt64(&apt.gid, goroutineID())
+    for {
+        select {
+            case fn := <-apt.calls:
+                atomic.StoreInt32(&apt.busy, 1)
+                fn()
+                atomic.StoreInt32(&apt.busy, 0)
+            case <-apt.done:
+                return
+        }
+    }
+}
+
+//id of the current goroutine, from the first line of its stack like "goroutine 18 [running]:".
+func goroutineID() (int64) {
+    var buf [64]byte
+    line := buf[:runtime.Stack(buf[:], false)]
+    line = bytes.TrimPrefix(line, []byte("goroutine "))
+    if i := bytes.IndexByte(line, ' '); i != -1 {
+        line = line[:i]
+    }
+    id, _ := strconv.ParseInt(string(line), 10, 64)
+    return id
+}
+
+//run fn on the thread of the apartment and wait for it, a panic of fn is passed to the caller.
+//a call from the apartment itself, such as an object called by a method or release of another, runs fn directly.
+//it is only possible while the apartment is busy, so the goroutine is not checked for other calls.
+func (apt *apartment) run(fn func()) (error) {
+    if atomic.LoadInt32(&apt.busy) == 1 && atomic.LoadInt64(&apt.gid) == goroutineID() {
+        fn()
+        return nil
+    }
+    var r interface{}
+    finished := make(chan struct{})
+    call := func() {
+        defer func() {
+            r = recover()
+            close(finished)
+        }()
+        fn()
+    }
+    select {
+        case apt.calls <- call:
+        case <-apt.done:
+            return fmt.Errorf("%w: apartment is closed", ErrDisconnected)
+    }
+    <-finished
+    if r != nil {
+        panic(r)
+    }
+    return nil
+}
+
+//Close stops the goroutine, calls after it return ErrDisconnected.
+func (apt *apartment) Close() {
+    apt.once.Do(func() {
+        close(apt.done)
+    })
+}
+
+//objects returned by the apartment are called in it too, and tracked until released.
+//name is the member returning it, scope is of the object it is got from.
+func (apt *apartment) wrap(val interface{}, name string, scope *Scope) (interface{}) {
+    if disp, ok := val.(Dispatcher); ok {
+        ad := aptDispatch{apt, disp, apt.track(name, disp), scope}
+        if scope != nil {
+            scope.add(ad.ref)
+        }
+        return ad
+    }
+    return val
+}
+
+//aptDispatch is a Dispatcher called in an apartment.
+type aptDispatch struct {
+    apt   *apartment
+    disp  Dispatcher
+    ref   *aptRef
+    scope *Scope
+}
+
+//
+func (ad aptDispatch) GetProperty(name string, params... interface{}) (ret interface{}, err error) {
+    if e := ad.apt.run(func() {
+        ret, err = ad.disp.GetProperty(name, params...)
+    }); e != nil {
+        return nil, e
+    }
+    return ad.apt.wrap(ret, memberPath(name, params), ad.scope), err
+}
+
+//
+func (ad aptDispatch) PutProperty(name string, params... interface{}) (err error) {
+    if e := ad.apt.run(func() {
+        err = ad.disp.PutProperty(name, params...)
+    }); e != nil {
+        return e
+    }
+    return
+}
+
+//
+func (ad aptDispatch) CallMethod(name string, params... interface{}) (ret interface{}, err error) {
+    if e := ad.apt.run(func() {
+        ret, err = ad.disp.CallMethod(name, params...)
+    }); e != nil {
+        return nil, e
+    }
+    return ad.apt.wrap(ret, memberPath(name, params), ad.scope), err
+}
+
+//Release releases the object once, later calls do nothing.
+func (ad aptDispatch) Release() (int32) {
+    return ad.apt.release(ad.ref)
+}
@@ -0,0 +1,209 @@
+package excel
+
+import (
+    "errors"
+    "sync"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+func TestGetIDispatchInApartment(t *testing.T) {
+    mso, err := New(Option{"Backend": "fake"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer mso.Quit()
+    sheet, err := mso.AddSheet("two")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, ok := sheet.Dispatcher.(aptDispatch); ! ok {
+        t.Fatalf("sheet added is %T", sheet.Dispatcher)
+    }
+    if sheet.Name() != "two" {
+        t.Fatal(sheet.Name())
+    }
+    for _, from := range []interface{}{mso.WorkBook, sheet, sheet.Dispatcher} {
+        name := "Cells"
+        if _, ok := from.(WorkBook); ok {
+            name = "Sheets"
+        }
+        idisp := GetIDispatch(from, name)
+        if _, ok := idisp.(aptDispatch); ! ok {
+            t.Fatalf("GetIDispatch of %T is %T", from, idisp)
+        }
+        idisp.Release()
+    }
+    font := GetIDispatch(sheet, "Cells", "Font")
+    defer font.Release()
+    if _, ok := font.(aptDispatch); ! ok {
+        t.Fatalf("GetIDispatch of a path is %T", font)
+    }
+}
+
+func TestApartmentConcurrentCells(t *testing.T) {
+    mso, err := New(Option{"Backend": "fake"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer mso.Quit()
+    sheet, _ := mso.Sheet(1)
+    var wg sync.WaitGroup
+    for c := 1; c <= 8; c ++ {
+        wg.Add(1)
+        go func(c int) {
+            defer wg.Done()
+            for r := 1; r <= 50; r ++ {
+                if err := sheet.PutCell(r, c, r*c); err != nil {
+                    t.Error(err)
+                    return
+                }
+                if val, err := sheet.GetCell(r, c); err != nil || val != float64(r*c) {
+                    t.Errorf("%v%v is %v, %v", ColumnItoa(c), r, val, err)
+                    return
+                }
+            }
+        }(c)
+    }
+    wg.Wait()
+    if val, _ := sheet.GetCell(50, 8); val != 400.0 {
+        t.Fatalf("H50 is %v", val)
+    }
+}
+
+func TestApartmentPanic(t *testing.T) {
+    apt := newApartment()
+    defer apt.Close()
+    func() {
+        defer func() {
+            if r := recover(); r != "boom" {
+                t.Fatalf("recovered %v", r)
+            }
+        }()
+        apt.run(func() {
+            panic("boom")
+        })
+        t.Fatal("panic is not passed to the caller")
+    }()
+    ran := false
+    err := apt.run(func() {
+        ran = true
+    })
+    if err != nil || ! ran {
+        t.Fatalf("apartment after a panic: %v", err)
+    }
+}
+
+func TestApartmentPanicOfCall(t *testing.T) {
+    mso, err := New(Option{"Backend": "fake"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer mso.Quit()
+    unwrap(mso.IdExcel).(*FakeDispatch).OnGet("Crash", func(args... interface{}) (interface{}, error) {
+        panic("crash")
+    })
+    _, err = GetProperty(mso.IdExcel, "Crash")
+    if err == nil {
+        t.Fatal("a panic of the apartment is lost")
+    }
+    if _, err = mso.IdExcel.GetProperty("Version"); err != nil {
+        t.Fatalf("apartment after a panic: %v", err)
+    }
+}
+
+func TestApartmentClosed(t *testing.T) {
+    mso, err := New(Option{"Backend": "fake"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    sheet, _ := mso.Sheet(1)
+    if err = mso.Quit(); err != nil {
+        t.Fatal(err)
+    }
+    if _, err = mso.IdExcel.GetProperty("Version"); ! errors.Is(err, ErrDisconnected) {
+        t.Fatalf("GetProperty after Quit: %v", err)
+    }
+    if err = sheet.PutCell(1, 1, "x"); ! errors.Is(err, ErrDisconnected) {
+        t.Fatalf("PutCell after Quit: %v", err)
+    }
+    if _, err = sheet.GetCell(1, 1); ! errors.Is(err, ErrDisconnected) {
+        t.Fatalf("GetCell after Quit: %v", err)
+    }
+    apt := newApartment()
+    apt.Close()
+    apt.Close()
+    if err = apt.run(func() {
+    }); ! errors.Is(err, ErrDisconnected) {
+        t.Fatalf("run after Close: %v", err)
+    }
+}
+
+func TestApartmentReentrant(t *testing.T) {
+    mso, err := New(Option{"Backend": "fake"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer mso.Quit()
+    sheet, _ := mso.Sheet(1)
+    var font Dispatcher
+    unwrap(mso.IdExcel).(*FakeDispatch).OnCall("Nested", func(args... interface{}) (interface{}, error) {
+        font = GetIDispatch(sheet, "Cells", "Font")
+        font.PutProperty("Bold", true)
+        font.Release()
+        return sheet.GetProperty("Name")
+    })
+    done := make(chan interface{})
+    go func() {
+        name, err := mso.IdExcel.CallMethod("Nested")
+        if err != nil {
+            name = err
+        }
+        done <- name
+    }()
+    select {
+        case name := <-done:
+            if name != "Sheet1" {
+                t.Fatalf("nested call returns %v", name)
+            }
+        case <-time.After(time.Second):
+            t.Fatal("a call from the apartment deadlocks")
+    }
+    if unwrap(font).(*FakeDispatch).Released != 1 {
+        t.Fatal("the object released in the apartment is not released")
+    }
+
+    apt := newApartment()
+    defer apt.Close()
+    inner := int64(0)
+    apt.run(func() {
+        apt.run(func() {
+            inner = goroutineID()
+        })
+    })
+    if inner == 0 || inner == goroutineID() {
+        t.Fatalf("nested run is on goroutine %v", inner)
+    }
+}
+
+func TestApartmentBusy(t *testing.T) {
+    apt := newApartment()
+    defer apt.Close()
+    release, other := make(chan struct{}), make(chan int64)
+    go apt.run(func() {
+        go apt.run(func() {
+            other <- goroutineID()
+        })
+        <-release
+    })
+    select {
+        case <-other:
+            t.Fatal("a call of another goroutine runs while the apartment is busy")
+        case <-time.After(50*time.Millisecond):
+    }
+    close(release)
+    if id := <-other; id != atomic.LoadInt64(&apt.gid) {
+        t.Fatalf("the call of another goroutine runs on goroutine %v", id)
+    }
+}
@@ -0,0 +1,169 @@
+package excel
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+)
+
+//a free name of a temp file like "~report.123456.xlsx" in the directory of full.
+func tempName(full string, ext string) (string, error) {
+    dir, base := filepath.Split(full)
+    f, err := os.CreateTemp(dir, "~"+strings.TrimSuffix(base, filepath.Ext(base))+".*"+ext)
+    if err != nil {
+        return "", err
+    }
+    name := f.Name()
+    f.Close()
+    os.Remove(name)
+    return name, nil
+}
+
+//write full by save to a temp file in its directory, which is verified and renamed to full,
+//so full is either the old file or the new one, never a truncated one.
+func atomicWrite(full string, save func(tmp string) error, verify func(tmp string) error) (err error) {
+    tmp, err := tempName(full, filepath.Ext(full))
+    if err != nil {
+        return
+    }
+    defer func() {
+        if err != nil {
+            os.Remove(tmp)
+        }
+    }()
+    if err = save(tmp); err != nil {
+        return
+    }
+    if verify != nil {
+        if err = verify(tmp); err != nil {
+            return
+        }
+    }
+    return os.Rename(tmp, full)
+}
+
+//SaveAs and Save by Option "AtomicSave", args are resolved by saveAsArgs, or nil to save the workbook as its file.
+//a copy of the workbook is saved as a temp file beside the file and verified, Excel never writes the file itself.
+//then the workbook is closed, which frees its own file, the temp file is renamed to the file and the file is opened,
+//so the file is either the old one or the verified new one. if the rename fails, the temp file is opened instead,
+//so no change is lost, and err names it.
+func (wb WorkBook) saveAtomic(args []interface{}) (err error) {
+    if args == nil {
+        if String(mustGet(wb.Dispatcher, "Path")) == "" {
+            _, err = wb.CallMethod("Save")
+            return
+        }
+        n, _ := toInt(mustGet(wb.Dispatcher, "FileFormat"))
+        args = []interface{}{String(mustGet(wb.Dispatcher, "FullName")), n}
+    }
+    full, ok := args[0].(string)
+    if ! ok || len(args) < 2 {
+        return fmt.Errorf("incorrect file name %+v", args[0])
+    }
+    n, _ := toInt(args[1])
+    ff := FileFormat(n)
+    tmp, err := tempName(full, filepath.Ext(full))
+    if err != nil {
+        return
+    }
+    if err = wb.saveCopy(tmp, ff, args[2:]); err == nil {
+        err = wb.MSO.verifyFile(tmp, ff)
+    }
+    if err == nil {
+        _, err = wb.CallMethod("Close", false)
+    }
+    if err != nil {
+        os.Remove(tmp)
+        return
+    }
+    opened := full
+    if err = os.Rename(tmp, full); err != nil {
+        opened = tmp
+        err = fmt.Errorf("%w, the workbook is opened as %v", err, tmp)
+    }
+    if e := wb.reopen(opened, args); e != nil && err == nil {
+        err = fmt.Errorf("%v is saved, but not opened again: %w", full, e)
+    }
+    return
+}
+
+//open full as the workbook closed by saveAtomic, which is mso.WorkBook then if it was.
+//Password and WriteResPassword of args of SaveAs are used to open it.
+func (wb WorkBook) reopen(full string, args []interface{}) (error) {
+    opts := OpenOptions{}
+    if len(args) > 2 {
+        opts.Password, _ = args[2].(string)
+    }
+    if len(args) > 3 {
+        opts.WriteResPassword, _ = args[3].(string)
+    }
+    one, err := wb.MSO.OpenWorkBook(full, opts)
+    if err != nil {
+        return err
+    }
+    if wb.MSO.WorkBook.Dispatcher == wb.Dispatcher {
+        wb.MSO.WorkBook.Release()
+        wb.MSO.WorkBook = one
+    } else {
+        one.Release()
+    }
+    return nil
+}
+
+//save a copy as full in format ff, by SaveCopyAs, and SaveAs of the copy if the workbook is in another format.
+//Excel does not hold the file then, so it can be renamed.
+func (wb WorkBook) saveCopy(full string, ff FileFormat, rest []interface{}) (err error) {
+    n, _ := toInt(mustGet(wb.Dispatcher, "FileFormat"))
+    if FileFormat(n) == ff && len(rest) == 0 {
+        _, err = wb.CallMethod("SaveCopyAs", full)
+        return
+    }
+    ext := ".tmp"
+    if info, ok := fileFormats[FileFormat(n)]; ok {
+        ext = "."+info.exts[0]
+    }
+    src, err := tempName(full, ext)
+    if err != nil {
+        return
+    }
+    defer os.Remove(src)
+    if _, err = wb.CallMethod("SaveCopyAs", src); err != nil {
+        return
+    }
+    one, err := wb.MSO.OpenWorkBook(src, OpenOptions{ReadOnly: true})
+    if err != nil {
+        return
+    }
+    defer func() {
+        one.CallMethod("Close", false)
+        one.Release()
+    }()
+    defer wb.MSO.quietAlerts()()
+    _, err = one.CallMethod("SaveAs", append([]interface{}{full, int(ff)}, rest...)...)
+    return
+}
+
+//the saved file is not empty, except text, and can be opened.
+func (mso *MSO) verifyFile(full string, ff FileFormat) (error) {
+    info, err := os.Stat(full)
+    if err != nil {
+        return err
+    }
+    if info.Size() == 0 && ! isText(ff) {
+        return fmt.Errorf("%v is empty", full)
+    }
+    wb, err := mso.OpenWorkBook(full, OpenOptions{ReadOnly: true})
+    if err != nil {
+        return fmt.Errorf("%v can not be opened: %w", full, err)
+    }
+    wb.CallMethod("Close", false)
+    wb.Release()
+    return nil
+}
+
+//formats of text files, which are empty for empty sheets.
+func isText(ff FileFormat) (bool) {
+    info := fileFormats[ff]
+    return hasExt(info, "csv") || hasExt(info, "txt") || hasExt(info, "prn")
+}
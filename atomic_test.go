@@ -0,0 +1,208 @@
+package excel
+
+import (
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+//no temp file is left in dir.
+func noTempFiles(t *testing.T, dir string) {
+    entries, err := os.ReadDir(dir)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, entry := range entries {
+        if strings.HasPrefix(entry.Name(), "~") {
+            t.Fatalf("temp file %v is left", entry.Name())
+        }
+    }
+}
+
+func TestAtomicSaveAs(t *testing.T) {
+    dir := t.TempDir()
+    mso, err := New(Option{"Backend": "xlsx", "AtomicSave": true})
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer mso.Quit()
+    sheet, _ := mso.Sheet(1)
+    sheet.PutCell(1, 1, "hello")
+    full := filepath.Join(dir, "r.xlsx")
+    if err = os.WriteFile(full, []byte("old"), 0644); err != nil {
+        t.Fatal(err)
+    }
+    if err = mso.WorkBook.SaveAs(full); err != nil {
+        t.Fatal(err)
+    }
+    if name := mso.WorkBook.Name(); name != "r.xlsx" {
+        t.Fatalf("Name is %q after SaveAs", name)
+    }
+    if saved, _ := GetProperty(mso.WorkBook, "Saved"); saved != true {
+        t.Fatalf("Saved is %v after SaveAs", saved)
+    }
+    if err = mso.WorkBook.SaveAs(filepath.Join(dir, "r.csv")); err != nil {
+        t.Fatal(err)
+    }
+    if data, _ := os.ReadFile(filepath.Join(dir, "r.csv")); string(data) != "hello\n" {
+        t.Fatalf("csv is %q", data)
+    }
+    if full, _ := GetProperty(mso.WorkBook, "FullName"); full != filepath.Join(dir, "r.csv") {
+        t.Fatalf("FullName is %v after SaveAs", full)
+    }
+    noTempFiles(t, dir)
+    one, err := Open(filepath.Join(dir, "r.xlsx"), Option{"Backend": "xlsx"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer one.Quit()
+    sheet, _ = one.Sheet(1)
+    if val, _ := sheet.GetCell(1, 1); val != "hello" {
+        t.Fatalf("A1 of the saved file is %v", val)
+    }
+}
+
+func TestAtomicSave(t *testing.T) {
+    dir := t.TempDir()
+    full := filepath.Join(dir, "r.xlsx")
+    mso, err := New(Option{"Backend": "xlsx", "AtomicSave": true})
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer mso.Quit()
+    if err = mso.WorkBook.Save(); err == nil {
+        t.Fatal("a workbook never saved is saved")
+    }
+    sheet, _ := mso.Sheet(1)
+    sheet.PutCell(1, 1, "old")
+    if err = mso.WorkBook.SaveAs(full); err != nil {
+        t.Fatal(err)
+    }
+    sheet, _ = mso.Sheet(1)
+    sheet.PutCell(1, 1, "new")
+    if err = mso.WorkBook.Save(); err != nil {
+        t.Fatal(err)
+    }
+    noTempFiles(t, dir)
+    one, err := Open(full, Option{"Backend": "xlsx"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer one.Quit()
+    sheet, _ = one.Sheet(1)
+    if val, _ := sheet.GetCell(1, 1); val != "new" {
+        t.Fatalf("A1 of the saved file is %v", val)
+    }
+}
+
+func TestAtomicWriteKeepsFile(t *testing.T) {
+    full := filepath.Join(t.TempDir(), "r.xlsx")
+    if err := os.WriteFile(full, []byte("old"), 0644); err != nil {
+        t.Fatal(err)
+    }
+    mso, err := New(Option{"Backend": "xlsx"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer mso.Quit()
+    err = atomicWrite(full, func(tmp string) (error) {
+        return os.WriteFile(tmp, []byte("garbage"), 0644)
+    }, func(tmp string) (error) {
+        return mso.verifyFile(tmp, XlOpenXMLWorkbook)
+    })
+    if err == nil {
+        t.Fatal("garbage is verified")
+    }
+    if data, _ := os.ReadFile(full); string(data) != "old" {
+        t.Fatalf("file is %q after a failed save", data)
+    }
+    noTempFiles(t, filepath.Dir(full))
+}
+
+//SaveCopyAs of the workbook writes garbage while *garbage, SaveAs and Save of it are recorded by writes.
+func garbageCopies(wb WorkBook, garbage *bool, writes *[]string) {
+    book := unwrap(wb.Dispatcher).(*FakeDispatch)
+    saveCopyAs := book.Methods["savecopyas"]
+    book.OnCall("SaveCopyAs", func(args... interface{}) (interface{}, error) {
+        if *garbage {
+            return nil, os.WriteFile(String(args[0]), []byte("garbage"), 0644)
+        }
+        return saveCopyAs(args...)
+    })
+    for _, name := range []string{"SaveAs", "Save"} {
+        name, method := name, book.Methods[strings.ToLower(name)]
+        book.OnCall(name, func(args... interface{}) (interface{}, error) {
+            *writes = append(*writes, name)
+            return method(args...)
+        })
+    }
+}
+
+func TestAtomicSaveNeverWritesFile(t *testing.T) {
+    dir := t.TempDir()
+    full := filepath.Join(dir, "r.xlsx")
+    if err := os.WriteFile(full, []byte("old"), 0644); err != nil {
+        t.Fatal(err)
+    }
+    mso, err := New(Option{"Backend": "xlsx", "AtomicSave": true})
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer mso.Quit()
+    sheet, _ := mso.Sheet(1)
+    sheet.PutCell(1, 1, "hello")
+    garbage, writes := true, []string{}
+    garbageCopies(mso.WorkBook, &garbage, &writes)
+    if err = mso.WorkBook.SaveAs(full); err == nil {
+        t.Fatal("garbage is verified")
+    }
+    if data, _ := os.ReadFile(full); string(data) != "old" {
+        t.Fatalf("file is %q after a failed SaveAs", data)
+    }
+    if name := mso.WorkBook.Name(); name != "Book1" {
+        t.Fatalf("the workbook is %v after a failed SaveAs", name)
+    }
+    garbage = false
+    if err = mso.WorkBook.SaveAs(full); err != nil {
+        t.Fatal(err)
+    }
+    garbage = true
+    garbageCopies(mso.WorkBook, &garbage, &writes)
+    if err = mso.WorkBook.Save(); err == nil {
+        t.Fatal("garbage is verified")
+    }
+    if len(writes) != 0 {
+        t.Fatalf("the workbook writes the file by %v", writes)
+    }
+    noTempFiles(t, dir)
+    if val := savedCell(t, full); val != "hello" {
+        t.Fatalf("A1 of the file is %v after a failed Save", val)
+    }
+}
+
+func TestAtomicSaveRenameFails(t *testing.T) {
+    dir := t.TempDir()
+    full := filepath.Join(dir, "r.xlsx")
+    if err := os.MkdirAll(filepath.Join(full, "sub"), 0755); err != nil {
+        t.Fatal(err)
+    }
+    mso, err := New(Option{"Backend": "xlsx", "AtomicSave": true})
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer mso.Quit()
+    sheet, _ := mso.Sheet(1)
+    sheet.PutCell(1, 1, "hello")
+    if err = mso.WorkBook.SaveAs(full); err == nil {
+        t.Fatal("a directory is replaced")
+    }
+    tmp := mso.WorkBook.FullName()
+    if filepath.Dir(tmp) != dir || ! strings.HasPrefix(filepath.Base(tmp), "~r.") || ! strings.Contains(err.Error(), tmp) {
+        t.Fatalf("the workbook is %v after the rename fails: %v", tmp, err)
+    }
+    sheet, _ = mso.Sheet(1)
+    if val, _ := sheet.GetCell(1, 1); val != "hello" {
+        t.Fatalf("A1 is %v after the rename fails", val)
+    }
+}
@@ -0,0 +1,683 @@
+package excel
+
+import (
+    "strings"
+    "strconv"
+    "reflect"
+    "time"
+    "path/filepath"
+    "errors"
+    "fmt"
+    "github.com/go-ole/go-ole"
+)
+
+//the in-memory model of Excel.Application, of workbooks, sheets, cells, styles and formulas,
+//which is shared by the backends "fake" and "xlsx", its objects are FakeDispatch.
+type memExcel struct {
+    app    *FakeDispatch
+    books  []*memBook
+    active *memBook
+    nbook  int
+    open   func(book *memBook, full string) error                      //nil: workbooks are not read from files.
+    save   func(book *memBook, full string, format interface{}) error  //nil: workbooks are not written to files.
+}
+
+//a workbook of memExcel.
+type memBook struct {
+    excel     *memExcel
+    disp      *FakeDispatch
+    sheets    []*memSheet
+    active    *memSheet
+    nsheet    int
+    full      string
+    delimiter rune    //of text files by Format and Delimiter of Workbooks.Open, 0 for default.
+}
+
+//a worksheet of memBook.
+type memSheet struct {
+    book     *memBook
+    disp     *FakeDispatch
+    cells    map[[2]int]interface{}
+    objs     map[string]*FakeDispatch
+    formulas map[[2]int]memFormula
+}
+
+//a formula is kept as it is put and converted between A1 and R1C1 when it is got, it is not calculated.
+type memFormula struct {
+    text  string
+    r1c1  bool
+    array string    //the address of the array formula it is in
+}
+
+//
+func newMemExcel() (xl *memExcel) {
+    xl = &memExcel{app: NewFakeDispatch("Application")}
+    xl.app.Set("Version", "16.0").Set("Name", "Microsoft Excel")
+    xl.app.OnCall("Quit", noneFunc)
+
+    wbs := NewFakeDispatch("Workbooks")
+    wbs.OnGet("Count", func(args... interface{}) (interface{}, error) {
+        return int32(len(xl.books)), nil
+    })
+    wbs.OnGet("Item", xl.book)
+    wbs.OnCall("Add", func(args... interface{}) (interface{}, error) {
+        return xl.add("").disp, nil
+    })
+    wbs.OnCall("Open", func(args... interface{}) (interface{}, error) {
+        if len(args) == 0 {
+            return nil, errors.New("Workbooks.Open: file name is empty")
+        }
+        book := xl.add(String(args[0]))
+        if len(args) > 2 {
+            if readOnly, ok := args[2].(bool); ok {
+                book.disp.Set("ReadOnly", readOnly)
+            }
+        }
+        book.delimiter = textDelimiter(args)
+        if xl.open != nil {
+            if err := xl.open(book, book.full); err != nil {
+                book.close()
+                return nil, err
+            }
+        }
+        return book.disp, nil
+    })
+    wbs.OnCall("Close", func(args... interface{}) (interface{}, error) {
+        xl.books, xl.active = nil, nil
+        return nil, nil
+    })
+
+    xl.app.OnGet("Workbooks", func(args... interface{}) (interface{}, error) {
+        if len(args) == 0 {
+            return wbs, nil
+        }
+        return xl.book(args...)
+    })
+    xl.app.OnGet("ActiveWorkbook", func(args... interface{}) (interface{}, error) {
+        if xl.active == nil {
+            return nil, errors.New("no active workbook")
+        }
+        return xl.active.disp, nil
+    })
+    sheets := func(args... interface{}) (interface{}, error) {
+        if xl.active == nil {
+            return nil, errors.New("no active workbook")
+        }
+        return xl.active.disp.GetProperty("Worksheets", args...)
+    }
+    xl.app.OnGet("Worksheets", sheets)
+    xl.app.OnGet("Sheets", sheets)
+    xl.app.OnGet("ActiveSheet", func(args... interface{}) (interface{}, error) {
+        if xl.active == nil || xl.active.active == nil {
+            return nil, errors.New("no active sheet")
+        }
+        return xl.active.active.disp, nil
+    })
+    return
+}
+
+//the delimiter by Format and Delimiter of Workbooks.Open, see OpenOptions.
+func textDelimiter(args []interface{}) (rune) {
+    if len(args) <= 3 {
+        return 0
+    }
+    format, _ := toInt(args[3])
+    switch format {
+        case 1:
+            return '\t'
+        case 2:
+            return ','
+        case 3:
+            return ' '
+        case 4:
+            return ';'
+        case 6:
+            if len(args) > 8 {
+                if s, ok := args[8].(string); ok && s != "" {
+                    return []rune(s)[0]
+                }
+            }
+    }
+    return 0
+}
+
+//
+func noneFunc(args... interface{}) (interface{}, error) {
+    return nil, nil
+}
+
+//ExportAsFixedFormat of workbooks, sheets and ranges, which are not published by the backends.
+func (xl *memExcel) export(args... interface{}) (interface{}, error) {
+    if xl.save != nil {
+        return nil, errors.New("xlsx backend: ExportAsFixedFormat is not supported")
+    }
+    if len(args) < 2 || args[0] != xlTypePDF && args[0] != xlTypeXPS || String(args[1]) == "" {
+        return nil, fmt.Errorf("ExportAsFixedFormat: incorrect arguments %v", args)
+    }
+    return nil, nil
+}
+
+//
+func (xl *memExcel) book(args... interface{}) (interface{}, error) {
+    if len(args) == 0 {
+        return nil, errors.New("Workbooks.Item: index is empty")
+    }
+    if i, ok := toInt(args[0]); ok {
+        if i >= 1 && i <= len(xl.books) {
+            return xl.books[i-1].disp, nil
+        }
+    } else {
+        name := strings.ToLower(String(args[0]))
+        for _, one := range xl.books {
+            if strings.ToLower(String(one.disp.Prop("Name"))) == name {
+                return one.disp, nil
+            }
+        }
+    }
+    return nil, ole.NewErrorWithDescription(hrBadIndex, fmt.Sprintf("workbook not found: %v", args[0]))
+}
+
+//
+func (xl *memExcel) add(full string) (book *memBook) {
+    xl.nbook ++
+    book = &memBook{excel: xl, disp: NewFakeDispatch("Workbook")}
+    if full == "" {
+        book.disp.Set("Name", "Book"+strconv.Itoa(xl.nbook)).Set("FullName", "Book"+strconv.Itoa(xl.nbook)).Set("Path", "")
+    } else {
+        book.full = full
+        book.disp.Set("Name", filepath.Base(full)).Set("FullName", full).Set("Path", filepath.Dir(full))
+    }
+    ff, err := FormatOf(full, 0)
+    if err != nil {
+        ff = XlOpenXMLWorkbook
+    }
+    book.disp.Set("Saved", true).Set("FileFormat", int32(ff))
+
+    wss := NewFakeDispatch("Worksheets")
+    wss.OnGet("Count", func(args... interface{}) (interface{}, error) {
+        return int32(len(book.sheets)), nil
+    })
+    wss.OnGet("Item", book.sheet)
+    wss.OnCall("Add", func(args... interface{}) (interface{}, error) {
+        return book.add().disp, nil
+    })
+    sheets := func(args... interface{}) (interface{}, error) {
+        if len(args) == 0 {
+            return wss, nil
+        }
+        return book.sheet(args...)
+    }
+    book.disp.OnGet("Worksheets", sheets)
+    book.disp.OnGet("Sheets", sheets)
+    book.disp.OnCall("Activate", func(args... interface{}) (interface{}, error) {
+        xl.active = book
+        return nil, nil
+    })
+    book.disp.OnCall("Save", func(args... interface{}) (interface{}, error) {
+        if book.disp.Prop("ReadOnly") == true {
+            return nil, ole.NewErrorWithDescription(0x800A03EC, fmt.Sprintf("%v is read-only", book.disp.Prop("Name")))
+        }
+        if xl.save != nil {
+            if book.full == "" {
+                return nil, fmt.Errorf("Workbook.Save: %v has not been saved as a file", book.disp.Prop("Name"))
+            }
+            if err := xl.save(book, book.full, nil); err != nil {
+                return nil, err
+            }
+        }
+        book.disp.Set("Saved", true)
+        return nil, nil
+    })
+    book.disp.OnCall("SaveAs", func(args... interface{}) (interface{}, error) {
+        if len(args) == 0 {
+            if book.full == "" {
+                return nil, errors.New("Workbook.SaveAs: file name is empty")
+            }
+            args = []interface{}{book.full}
+        }
+        full, format := String(args[0]), interface{}(nil)
+        if len(args) > 1 {
+            format = args[1]
+        }
+        if xl.save != nil {
+            if err := xl.save(book, full, format); err != nil {
+                return nil, err
+            }
+        }
+        book.full = full
+        book.disp.Set("Name", filepath.Base(full)).Set("FullName", full).Set("Path", filepath.Dir(full)).Set("Saved", true)
+        if format != nil {
+            book.disp.Set("FileFormat", format)
+        }
+        return nil, nil
+    })
+    book.disp.OnCall("SaveCopyAs", func(args... interface{}) (interface{}, error) {
+        if len(args) == 0 {
+            return nil, errors.New("Workbook.SaveCopyAs: file name is empty")
+        }
+        if xl.save != nil {
+            return nil, xl.save(book, String(args[0]), book.disp.Prop("FileFormat"))
+        }
+        return nil, nil
+    })
+    book.disp.OnCall("ExportAsFixedFormat", xl.export)
+    book.disp.OnCall("Close", func(args... interface{}) (interface{}, error) {
+        book.close()
+        return nil, nil
+    })
+
+    book.add()
+    xl.books = append(xl.books, book)
+    xl.active = book
+    return
+}
+
+//
+func (book *memBook) close() {
+    xl := book.excel
+    for i, one := range xl.books {
+        if one == book {
+            xl.books = append(xl.books[:i], xl.books[i+1:]...)
+            break
+        }
+    }
+    if xl.active == book {
+        xl.active = nil
+        if len(xl.books) > 0 {
+            xl.active = xl.books[len(xl.books)-1]
+        }
+    }
+}
+
+//
+func (book *memBook) sheet(args... interface{}) (interface{}, error) {
+    if len(args) == 0 {
+        return nil, errors.New("Worksheets.Item: index is empty")
+    }
+    if i, ok := toInt(args[0]); ok {
+        if i >= 1 && i <= len(book.sheets) {
+            return book.sheets[i-1].disp, nil
+        }
+    } else {
+        name := strings.ToLower(String(args[0]))
+        for _, one := range book.sheets {
+            if strings.ToLower(String(one.disp.Prop("Name"))) == name {
+                return one.disp, nil
+            }
+        }
+    }
+    return nil, ole.NewErrorWithDescription(hrBadIndex, fmt.Sprintf("sheet not found: %v", args[0]))
+}
+
+//add a sheet before the active one, as Excel does.
+func (book *memBook) add() (sheet *memSheet) {
+    book.nsheet ++
+    sheet = book.newSheet("Sheet"+strconv.Itoa(book.nsheet))
+    pos := len(book.sheets)
+    for i, one := range book.sheets {
+        if one == book.active {
+            pos = i
+        }
+    }
+    book.sheets = append(book.sheets[:pos], append([]*memSheet{sheet}, book.sheets[pos:]...)...)
+    book.active = sheet
+    return
+}
+
+//a sheet not yet in the workbook.
+func (book *memBook) newSheet(name string) (sheet *memSheet) {
+    sheet = &memSheet{book: book, disp: NewFakeDispatch("Worksheet"), cells: map[[2]int]interface{}{}, objs: map[string]*FakeDispatch{}, formulas: map[[2]int]memFormula{}}
+    sheet.disp.Set("Name", name)
+
+    sheet.disp.OnGet("Cells", func(args... interface{}) (interface{}, error) {
+        if len(args) == 0 {
+            return sheet.rang(1, 1, MaxRows, MaxColumns), nil
+        } else if len(args) != 2 {
+            return nil, errors.New("Worksheet.Cells: want row and column")
+        }
+        r, rok := toInt(args[0])
+        c, cok := toInt(args[1])
+        if ! rok || ! cok || r < 1 || c < 1 || r > MaxRows || c > MaxColumns {
+            return nil, fmt.Errorf("Worksheet.Cells: incorrect cell %v, %v", args[0], args[1])
+        }
+        return sheet.rang(r, c, r, c), nil
+    })
+    sheet.disp.OnGet("Range", func(args... interface{}) (interface{}, error) {
+        if len(args) == 0 {
+            return nil, errors.New("Worksheet.Range: address is empty")
+        }
+        ref, err := ParseRangeRef(String(args[0]))
+        if err != nil {
+            return nil, err
+        }
+        return sheet.rang(ref.Bounds()), nil
+    })
+    sheet.disp.OnGet("UsedRange", func(args... interface{}) (interface{}, error) {
+        return sheet.used(), nil
+    })
+    sheet.disp.OnCall("Select", func(args... interface{}) (interface{}, error) {
+        book.active = sheet
+        return nil, nil
+    })
+    sheet.disp.Methods["activate"] = sheet.disp.Methods["select"]
+    sheet.disp.OnCall("ExportAsFixedFormat", book.excel.export)
+    sheet.disp.OnCall("Delete", func(args... interface{}) (interface{}, error) {
+        if len(book.sheets) < 2 {
+            return nil, errors.New("Worksheet.Delete: a workbook must contain at least one sheet")
+        }
+        for i, one := range book.sheets {
+            if one == sheet {
+                book.sheets = append(book.sheets[:i], book.sheets[i+1:]...)
+                break
+            }
+        }
+        if book.active == sheet {
+            book.active = book.sheets[0]
+        }
+        return nil, nil
+    })
+    return
+}
+
+//the used range, A1 if the sheet is empty.
+func (sheet *memSheet) used() (*FakeDispatch) {
+    if len(sheet.cells) == 0 {
+        return sheet.rang(1, 1, 1, 1)
+    }
+    r1, c1, r2, c2 := MaxRows, MaxColumns, 1, 1
+    for rc := range sheet.cells {
+        if rc[0] < r1 {
+            r1 = rc[0]
+        }
+        if rc[0] > r2 {
+            r2 = rc[0]
+        }
+        if rc[1] < c1 {
+            c1 = rc[1]
+        }
+        if rc[1] > c2 {
+            c2 = rc[1]
+        }
+    }
+    return sheet.rang(r1, c1, r2, c2)
+}
+
+//
+func (sheet *memSheet) rang(r1, c1, r2, c2 int) (*FakeDispatch) {
+    address := RangeRef{From: CellRef{r1, c1, true, true}, To: CellRef{r2, c2, true, true}}.String()
+    rg := NewFakeDispatch("Range")
+    rg.Set("Address", address).Set("Row", int32(r1)).Set("Column", int32(c1))
+    rg.Set("Count", int32((r2-r1+1)*(c2-c1+1)))
+    rg.Set("Rows", NewFakeDispatch("Rows").Set("Count", int32(r2-r1+1)))
+    rg.Set("Columns", NewFakeDispatch("Columns").Set("Count", int32(c2-c1+1)))
+    rg.Set("Worksheet", sheet.disp)
+
+    value := func(args... interface{}) (interface{}, error) {
+        if r1 == r2 && c1 == c2 {
+            return sheet.get(r1, c1), nil
+        }
+        rows := make([][]interface{}, r2-r1+1)
+        for i := range rows {
+            rows[i] = make([]interface{}, c2-c1+1)
+            for j := range rows[i] {
+                rows[i][j] = sheet.get(r1+i, c1+j)
+            }
+        }
+        return rows, nil
+    }
+    put := func(args... interface{}) (interface{}, error) {
+        if len(args) == 0 {
+            return nil, errors.New("Range.Value: value is empty")
+        }
+        val := reflect.ValueOf(args[len(args)-1])
+        for i := 0; i <= r2-r1; i ++ {
+            for j := 0; j <= c2-c1; j ++ {
+                sheet.put(r1+i, c1+j, elementAt(val, i, j))
+            }
+        }
+        return nil, nil
+    }
+    rg.OnGet("Value", value).OnGet("Value2", value)
+    rg.OnPut("Value", put).OnPut("Value2", put)
+    for _, name := range []string {"Font", "Interior", "Borders"} {
+        key := strings.ToLower(address+"."+name)
+        name := name
+        rg.OnGet(name, func(args... interface{}) (interface{}, error) {
+            key := key
+            if len(args) > 0 {
+                key += "("+String(args[0])+")"
+            }
+            if _, ok := sheet.objs[key]; ! ok {
+                sheet.objs[key] = defaultStyle(name)
+            }
+            return sheet.objs[key], nil
+        })
+    }
+    //the alignment and format of the range, kept by address as the objects above.
+    props, ok := sheet.objs[strings.ToLower(address)]
+    if ! ok {
+        props = defaultStyle("Range")
+        sheet.objs[strings.ToLower(address)] = props
+    }
+    for _, name := range []string {"NumberFormat", "HorizontalAlignment", "VerticalAlignment", "WrapText", "ShrinkToFit", "Orientation", "IndentLevel", "MergeCells"} {
+        name := name
+        rg.OnGet(name, func(args... interface{}) (interface{}, error) {
+            return props.Prop(name), nil
+        })
+        rg.OnPut(name, func(args... interface{}) (interface{}, error) {
+            if len(args) == 0 {
+                return nil, fmt.Errorf("Range.%v: value is empty", name)
+            }
+            props.Set(name, args[len(args)-1])
+            return nil, nil
+        })
+    }
+    for _, name := range []string {"Select", "Activate", "Merge", "UnMerge", "AutoFilter"} {
+        rg.OnCall(name, noneFunc)
+    }
+    clear := func(args... interface{}) (interface{}, error) {
+        for r := r1; r <= r2; r ++ {
+            for c := c1; c <= c2; c ++ {
+                delete(sheet.cells, [2]int{r, c})
+                delete(sheet.formulas, [2]int{r, c})
+            }
+        }
+        return nil, nil
+    }
+    rg.OnCall("Clear", clear).OnCall("ClearContents", clear)
+    sheet.formulaProps(rg, address, r1, c1, r2, c2)
+    rg.OnCall("ExportAsFixedFormat", sheet.book.excel.export)
+    return rg
+}
+
+//the formulas of the range, a formula cell reads as "" by Value as it is not calculated.
+func (sheet *memSheet) formulaProps(rg *FakeDispatch, address string, r1, c1, r2, c2 int) {
+    for _, name := range []string {"Formula", "FormulaR1C1", "FormulaLocal", "FormulaR1C1Local", "FormulaArray", "Formula2", "Formula2R1C1"} {
+        name, r1c1 := name, strings.Contains(name, "R1C1")
+        rg.OnGet(name, func(args... interface{}) (interface{}, error) {
+            if name == "FormulaArray" && (r1 != r2 || c1 != c2) {
+                if f, ok := sheet.formulas[[2]int{r1, c1}]; ok && f.array == address {
+                    return f.text, nil
+                }
+                return nil, nil
+            }
+            rows := make([][]interface{}, r2-r1+1)
+            for i := range rows {
+                rows[i] = make([]interface{}, c2-c1+1)
+                for j := range rows[i] {
+                    text, err := sheet.formula(r1+i, c1+j, r1c1)
+                    if err != nil {
+                        return nil, fmt.Errorf("Range.%v: %w", name, err)
+                    }
+                    rows[i][j] = text
+                }
+            }
+            if r1 == r2 && c1 == c2 {
+                return rows[0][0], nil
+            }
+            return rows, nil
+        })
+        rg.OnPut(name, func(args... interface{}) (interface{}, error) {
+            if len(args) == 0 {
+                return nil, fmt.Errorf("Range.%v: value is empty", name)
+            }
+            array := ""
+            if name == "FormulaArray" {
+                if _, ok := args[len(args)-1].(string); ! ok {
+                    return nil, fmt.Errorf("Range.%v: want a string, got %T", name, args[len(args)-1])
+                }
+                array = address
+            }
+            val := reflect.ValueOf(args[len(args)-1])
+            for i := 0; i <= r2-r1; i ++ {
+                for j := 0; j <= c2-c1; j ++ {
+                    text := ""
+                    if elem := elementAt(val, i, j); elem != nil {
+                        text = String(elem)
+                    }
+                    if val.Kind() == reflect.String && array == "" && ! r1c1 && i+j > 0 {
+                        text = rebaseCopy(text, CellRef{Row: r1, Column: c1}, CellRef{Row: r1+i, Column: c1+j})
+                    }
+                    sheet.putFormula(r1+i, c1+j, text, r1c1, array)
+                }
+            }
+            return nil, nil
+        })
+    }
+    rg.OnGet("HasFormula", func(args... interface{}) (interface{}, error) {
+        n := 0
+        for r := r1; r <= r2; r ++ {
+            for c := c1; c <= c2; c ++ {
+                if _, ok := sheet.formulas[[2]int{r, c}]; ok {
+                    n ++
+                }
+            }
+        }
+        switch n {
+            case 0:
+                return false, nil
+            case (r2-r1+1) * (c2-c1+1):
+                return true, nil
+        }
+        return nil, nil
+    })
+}
+
+//the formula of a cell, or its value as text.
+func (sheet *memSheet) formula(r, c int, r1c1 bool) (string, error) {
+    f, ok := sheet.formulas[[2]int{r, c}]
+    if ! ok {
+        return String(sheet.get(r, c)), nil
+    } else if f.r1c1 == r1c1 {
+        return f.text, nil
+    }
+    base := CellRef{Row: r, Column: c}
+    if f.r1c1 {
+        parsed, err := ParseFormulaR1C1(f.text, base)
+        if err != nil {
+            return "", err
+        }
+        return parsed.String(), nil
+    }
+    parsed, err := ParseFormula(f.text)
+    if err != nil {
+        return "", err
+    }
+    return parsed.R1C1(base), nil
+}
+
+//a formula in A1 notation put into a range is adjusted for each cell as it is copied from the first, as Excel does.
+func rebaseCopy(text string, from CellRef, to CellRef) (string) {
+    f, err := ParseFormula(text)
+    if err != nil || ! strings.HasPrefix(text, "=") {
+        return text
+    }
+    f.Rebase(from, to)
+    return f.String()
+}
+
+//a text with "=" is a formula, others are constants, numbers of which are stored as numbers as Excel does.
+func (sheet *memSheet) putFormula(r, c int, text string, r1c1 bool, array string) {
+    if ! strings.HasPrefix(text, "=") {
+        if f, ok := parseNumber(text); ok {
+            sheet.put(r, c, f)
+        } else {
+            sheet.put(r, c, text)
+        }
+        return
+    }
+    sheet.put(r, c, nil)
+    sheet.cells[[2]int{r, c}] = ""
+    sheet.formulas[[2]int{r, c}] = memFormula{text, r1c1, array}
+}
+
+//the default style of a new workbook, as Excel returns it.
+func defaultStyle(name string) (fd *FakeDispatch) {
+    fd = NewFakeDispatch(name)
+    switch name {
+        case "Font":
+            fd.Set("Name", "Calibri").Set("Size", 11.0).Set("Bold", false).Set("Italic", false).Set("Underline", int32(XlUnderlineStyleNone))
+            fd.Set("Strikethrough", false).Set("Color", 0.0).Set("ColorIndex", int32(1))
+        case "Interior":
+            fd.Set("Pattern", int32(XlPatternNone)).Set("Color", 16777215.0).Set("ColorIndex", int32(XlColorIndexNone)).Set("PatternColor", 0.0)
+        case "Borders":
+            fd.Set("LineStyle", int32(XlLineStyleNone)).Set("Weight", int32(XlThin)).Set("Color", 0.0).Set("ColorIndex", int32(XlColorIndexAutomatic))
+        case "Range":
+            fd.Set("NumberFormat", "General").Set("HorizontalAlignment", int32(XlHAlignGeneral)).Set("VerticalAlignment", int32(XlVAlignBottom))
+            fd.Set("WrapText", false).Set("ShrinkToFit", false).Set("Orientation", int32(XlOrientationHorizontal)).Set("IndentLevel", int32(0)).Set("MergeCells", false)
+    }
+    return
+}
+
+//an empty cell reads as VT_EMPTY, that is "", a date as VT_DATE.
+func (sheet *memSheet) get(r, c int) (interface{}) {
+    if val, ok := sheet.cells[[2]int{r, c}]; ok {
+        if t, ok := val.(time.Time); ok {
+            return dateValue(FromOADate(ToOADate(t), DateLocation))
+        }
+        return val
+    }
+    return ""
+}
+
+//numbers are stored as float64, as Excel does.
+func (sheet *memSheet) put(r, c int, val interface{}) {
+    delete(sheet.formulas, [2]int{r, c})
+    if val == nil || val == "" {
+        delete(sheet.cells, [2]int{r, c})
+        return
+    }
+    rv := reflect.ValueOf(val)
+    switch rv.Kind() {
+        case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+            val = float64(rv.Int())
+        case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+            val = float64(rv.Uint())
+        case reflect.Float32, reflect.Float64:
+            val = rv.Float()
+    }
+    sheet.cells[[2]int{r, c}] = val
+}
+
+//element (i, j) of the value put to a range: scalar fills all, 1-D array fills each row, beyond the array is #N/A.
+func elementAt(val reflect.Value, i, j int) (interface{}) {
+    if val.Kind() != reflect.Slice && val.Kind() != reflect.Array {
+        if ! val.IsValid() {
+            return nil
+        }
+        return val.Interface()
+    }
+    if val.Len() > 0 && isRow(val.Index(0)) {
+        if i >= val.Len() {
+            return "#N/A"
+        }
+        val = elemOf(val.Index(i))
+    }
+    if j >= val.Len() {
+        return "#N/A"
+    }
+    if elem := elemOf(val.Index(j)); elem.IsValid() {
+        return elem.Interface()
+    }
+    return nil
+}
@@ -0,0 +1,185 @@
+package excel
+
+import (
+    "context"
+    "errors"
+    "sync"
+)
+
+//ErrTimeout is matched by errors of operations stopped by the deadline or cancel of their context,
+//which also match context.DeadlineExceeded or context.Canceled.
+var ErrTimeout = errors.New("excel timeout")
+
+//
+func timeoutError(op string, err error) (error) {
+    e := &Error{Op: op, Err: err, kind: ErrTimeout}
+    logError(op, e, false, false)
+    return e
+}
+
+//run fn of the operation op until ctx is done, onTimeout is called if it is not finished then.
+//a COM call can not be interrupted, fn keeps running in its goroutine after timeout.
+func runContext(ctx context.Context, op string, fn func() error, onTimeout func()) (error) {
+    if err := ctx.Err(); err != nil {
+        return timeoutError(op, err)
+    }
+    done := make(chan error, 1)
+    go func() {
+        done <- fn()
+    }()
+    select {
+        case err := <-done:
+            if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+                return timeoutError(op, ctxErr)
+            }
+            return err
+        case <-ctx.Done():
+            if onTimeout != nil {
+                onTimeout()
+            }
+            return timeoutError(op, ctx.Err())
+    }
+}
+
+//kill Excel on timeout if Option "KillOnTimeout" is true.
+func (mso *MSO) onTimeout() {
+    if kill, _ := mso.Option["KillOnTimeout"].(bool); kill {
+        mso.Kill()
+    }
+}
+
+//Kill kills the Excel process of the MSO, for an Excel which hangs in a modal dialog.
+func (mso *MSO) Kill() (err error) {
+    defer Except("Kill", &err)
+    if mso.pid == 0 {
+        return errors.New("process of Excel is unknown")
+    }
+    return killProcess(mso.pid)
+}
+
+//OpenContext is Open stopped by ctx. a COM call can not be interrupted, so the Excel started for it is killed on timeout
+//by Option "KillOnTimeout", and quit when Initialize or OpenWorkBook returns after timeout. until then its goroutine
+//keeps running, and an Excel which never returns is leaked unless it is killed.
+func OpenContext(ctx context.Context, full string, opt... Option) (mso *MSO, err error) {
+    var mutex sync.Mutex
+    var opened *MSO
+    timedOut := false
+    err = runContext(ctx, "Open", func() (err error) {
+        defer Except("Open", &err)
+        one := Initialize(opt...)
+        mutex.Lock()
+        opened = one
+        late := timedOut
+        mutex.Unlock()
+        if late {
+            one.onTimeout()
+            one.Quit()
+            return ctx.Err()
+        }
+        one.WorkBook, err = one.OpenWorkBook(full, one.openOptions()...)
+        if ctx.Err() != nil {
+            one.Quit()
+        }
+        return
+    }, func() {
+        mutex.Lock()
+        one := opened
+        timedOut = true
+        mutex.Unlock()
+        if one != nil {
+            one.onTimeout()
+        }
+    })
+    if err == nil {
+        mso = opened
+    }
+    return
+}
+
+//OpenWorkBookContext is OpenWorkBook stopped by ctx.
+func (mso *MSO) OpenWorkBookContext(ctx context.Context, full string, opts... OpenOptions) (wb WorkBook, err error) {
+    var opened WorkBook
+    err = runContext(ctx, "OpenWorkBook", func() (err error) {
+        opened, err = mso.OpenWorkBook(full, opts...)
+        return
+    }, mso.onTimeout)
+    if err == nil {
+        wb = opened
+    }
+    return
+}
+
+//QuitContext is Quit stopped by ctx.
+func (mso *MSO) QuitContext(ctx context.Context) (error) {
+    return runContext(ctx, "Quit", mso.Quit, mso.onTimeout)
+}
+
+//SaveAsContext is SaveAs stopped by ctx.
+func (wb WorkBook) SaveAsContext(ctx context.Context, args... interface{}) (error) {
+    onTimeout := func() {}
+    if wb.MSO != nil {
+        onTimeout = wb.MSO.onTimeout
+    }
+    return runContext(ctx, "WorkBook.SaveAs", func() (error) {
+        return wb.SaveAs(args...)
+    }, onTimeout)
+}
+
+//ReadRowContext is ReadRow stopped by ctx, errors are returned. rows are read in another goroutine and proc is called
+//in the goroutine of the caller, so it is never called after ReadRowContext returns.
+func (sheet Sheet) ReadRowContext(ctx context.Context, args... interface{}) (err error) {
+    defer Except("Sheet.ReadRow", &err)
+    var proc func([]interface{}) int
+    for _, arg := range args {
+        if fn, ok := arg.(func([]interface{}) int); ok {
+            proc = fn
+        }
+    }
+    if proc == nil {
+        return errors.New("ReadRow proc is nil, want func([]interface{}) int")
+    }
+    if err = ctx.Err(); err != nil {
+        return timeoutError("Sheet.ReadRow", err)
+    }
+    rows, stop, done := make(chan []interface{}), make(chan struct{}), make(chan error, 1)
+    defer close(stop)
+    go func() {
+        done <- sheet.sendRows(ctx, rows, stop, args...)
+    }()
+    for {
+        select {
+            case row := <-rows:
+                if ctx.Err() != nil {
+                    return timeoutError("Sheet.ReadRow", ctx.Err())
+                }
+                if rc := proc(row); rc == -1 {
+                    return nil
+                }
+            case err = <-done:
+                if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+                    return timeoutError("Sheet.ReadRow", ctxErr)
+                }
+                return
+            case <-ctx.Done():
+                return timeoutError("Sheet.ReadRow", ctx.Err())
+        }
+    }
+}
+
+//send the rows of ReadRow args to rows until the end, stop or ctx is done.
+func (sheet Sheet) sendRows(ctx context.Context, rows chan<- []interface{}, stop <-chan struct{}, args... interface{}) (err error) {
+    defer Except("Sheet.ReadRow", &err)
+    ra, _ := sheet.readArgs(args...)
+    it := sheet.newRowIterator(ctx, ra)
+    defer it.Close()
+    for it.Next() {
+        select {
+            case rows <- it.Row().Values:
+            case <-stop:
+                return nil
+            case <-ctx.Done():
+                return ctx.Err()
+        }
+    }
+    return it.Err()
+}
@@ -0,0 +1,109 @@
+package excel
+
+import (
+    "context"
+    "errors"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+func TestReadRowContext(t *testing.T) {
+    mso, sheet, _ := iteratorSheet(t, 5)
+    defer mso.Quit()
+    rows := [][]interface{}{}
+    err := sheet.ReadRowContext(context.Background(), int16(2), func(row []interface{}) (int) {
+        rows = append(rows, row)
+        return 0
+    })
+    if err != nil || len(rows) != 5 || rows[4][1] != "r5" {
+        t.Fatalf("%v rows: %v", len(rows), err)
+    }
+    rows = rows[:0]
+    err = sheet.ReadRowContext(context.Background(), int16(2), func(row []interface{}) (int) {
+        if rows = append(rows, row); len(rows) == 3 {
+            return -1
+        }
+        return 0
+    })
+    if err != nil || len(rows) != 3 {
+        t.Fatalf("%v rows until -1: %v", len(rows), err)
+    }
+    if err = sheet.ReadRowContext(context.Background(), "A"); err == nil {
+        t.Fatal("no proc is accepted")
+    }
+    ctx, cancel := context.WithCancel(context.Background())
+    cancel()
+    if err = sheet.ReadRowContext(ctx, func([]interface{}) (int) {return 0}); ! errors.Is(err, ErrTimeout) {
+        t.Fatalf("canceled: %v", err)
+    }
+}
+
+func TestReadRowContextTimeout(t *testing.T) {
+    mso, sheet, fake := iteratorSheet(t, 9)
+    defer mso.Quit()
+    block := make(chan struct{})
+    reads := 0
+    getRange := fake.Getters["range"]
+    fake.OnGet("Range", func(args... interface{}) (interface{}, error) {
+        if reads ++; reads == 2 {
+            <-block
+        }
+        return getRange(args...)
+    })
+
+    var calls int32
+    ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+    defer cancel()
+    err := sheet.ReadRowContext(ctx, int16(3), func(row []interface{}) (int) {
+        atomic.AddInt32(&calls, 1)
+        return 0
+    })
+    if ! errors.Is(err, ErrTimeout) || ! errors.Is(err, context.DeadlineExceeded) {
+        t.Fatalf("want ErrTimeout, got %v", err)
+    }
+    n := atomic.LoadInt32(&calls)
+    if n != 3 {
+        t.Fatalf("%v rows before the blocked read, want 3", n)
+    }
+    close(block)
+    time.Sleep(50*time.Millisecond)
+    if n = atomic.LoadInt32(&calls); n != 3 {
+        t.Fatalf("proc is called %v times after ReadRowContext returns", n-3)
+    }
+}
+
+func TestOpenContextTimeout(t *testing.T) {
+    block, quit := make(chan struct{}), make(chan struct{}, 1)
+    var opened int32
+    backend := func() (Dispatcher, error) {
+        <-block
+        app := NewFakeExcel()
+        app.OnCall("Quit", func(args... interface{}) (interface{}, error) {
+            quit <- struct{}{}
+            return nil, nil
+        })
+        wbs, _ := app.GetProperty("Workbooks")
+        open := wbs.(*FakeDispatch).Methods["open"]
+        wbs.(*FakeDispatch).OnCall("Open", func(args... interface{}) (interface{}, error) {
+            atomic.StoreInt32(&opened, 1)
+            return open(args...)
+        })
+        return app, nil
+    }
+    ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+    defer cancel()
+    mso, err := OpenContext(ctx, "late.xlsx", Option{"Backend": Backend(backend)})
+    if mso != nil || ! errors.Is(err, ErrTimeout) {
+        t.Fatalf("%v, %v", mso, err)
+    }
+    close(block)
+    select {
+        case <-quit:
+        case <-time.After(time.Second):
+            t.Fatal("the Excel started after timeout is not quit")
+    }
+    if atomic.LoadInt32(&opened) != 0 {
+        t.Fatal("the workbook is opened after timeout")
+    }
+}
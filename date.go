@@ -0,0 +1,91 @@
+package excel
+
+import (
+    "math"
+    "time"
+    "errors"
+    "fmt"
+)
+
+var (
+    //DateLocation is the location of the wall clock of dates in Excel, which have no time zone.
+    DateLocation = time.Local
+
+    //DateFormat is the opt-in layout to read dates as string, e.g. "2006-01-02 15:04:05", empty for time.Time.
+    DateFormat = ""
+
+    //ErrDate is wrapped by the errors of converting dates.
+    ErrDate = errors.New("incorrect date")
+)
+
+//day 0 of OLE Automation dates.
+var oaEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+//FromOADate converts an OLE Automation date (VT_DATE) to time in loc.
+//the integer part is days from 1899-12-30, negative before it, the fraction is the time of that day regardless of the sign.
+func FromOADate(oa float64, loc *time.Location) (time.Time) {
+    day := math.Trunc(oa)
+    ms := int64(math.Round(math.Abs(oa - day) * 86400000))
+    utc := oaEpoch.AddDate(0, 0, int(day)).Add(time.Duration(ms) * time.Millisecond)
+    if loc == nil {
+        loc = time.UTC
+    }
+    return time.Date(utc.Year(), utc.Month(), utc.Day(), utc.Hour(), utc.Minute(), utc.Second(), utc.Nanosecond(), loc)
+}
+
+//ToOADate converts the wall clock of t in DateLocation to an OLE Automation date.
+func ToOADate(t time.Time) (float64) {
+    if DateLocation != nil {
+        t = t.In(DateLocation)
+    }
+    utc := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+    secs := utc.Unix() - oaEpoch.Unix()
+    days := secs / 86400
+    if secs % 86400 < 0 {
+        days --
+    }
+    frac := (float64(secs - days * 86400) + float64(utc.Nanosecond()) / 1e9) / 86400
+    if days < 0 {
+        return float64(days) - frac
+    }
+    return float64(days) + frac
+}
+
+//FromExcelSerial converts a serial number of the 1900 or 1904 date system of a workbook to time in loc.
+//in the 1900 date system serials before 61 are one day off for the nonexistent 1900-02-29, which is serial 60.
+func FromExcelSerial(serial float64, date1904 bool, loc *time.Location) (time.Time, error) {
+    if serial < 0 {
+        return time.Time{}, fmt.Errorf("%w: negative serial %v", ErrDate, serial)
+    }
+    if date1904 {
+        return FromOADate(serial + 1462, loc), nil
+    }
+    if serial >= 60 && serial < 61 {
+        return time.Time{}, fmt.Errorf("%w: serial %v is 1900-02-29", ErrDate, serial)
+    } else if serial < 60 {
+        serial += 1
+    }
+    return FromOADate(serial, loc), nil
+}
+
+//ToExcelSerial converts t to a serial number of the 1900 or 1904 date system of a workbook.
+func ToExcelSerial(t time.Time, date1904 bool) (float64, error) {
+    oa := ToOADate(t)
+    if date1904 {
+        oa -= 1462
+    } else if oa < 61 {
+        oa -= 1
+    }
+    if oa < 0 {
+        return 0, fmt.Errorf("%w: %v is before the date system", ErrDate, t)
+    }
+    return oa, nil
+}
+
+//a date read from Excel, as string if DateFormat is set.
+func dateValue(t time.Time) (interface{}) {
+    if DateFormat != "" {
+        return t.Format(DateFormat)
+    }
+    return t
+}
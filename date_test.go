@@ -0,0 +1,118 @@
+package excel
+
+import (
+    "errors"
+    "testing"
+    "time"
+)
+
+//run f with DateLocation of loc.
+func withDateLocation(loc *time.Location, f func()) {
+    saved := DateLocation
+    defer func() {
+        DateLocation = saved
+    }()
+    DateLocation = loc
+    f()
+}
+
+func TestOADate(t *testing.T) {
+    tests := []struct {
+        oa   float64
+        want time.Time
+    }{
+        {0, time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)},
+        {1.5, time.Date(1899, 12, 31, 12, 0, 0, 0, time.UTC)},
+        {-1, time.Date(1899, 12, 29, 0, 0, 0, 0, time.UTC)},
+        {-1.25, time.Date(1899, 12, 29, 6, 0, 0, 0, time.UTC)},
+        {-2.75, time.Date(1899, 12, 28, 18, 0, 0, 0, time.UTC)},
+        {45351.573263888888, time.Date(2024, 2, 29, 13, 45, 30, 0, time.UTC)},
+    }
+    withDateLocation(time.UTC, func() {
+        for _, test := range tests {
+            if got := FromOADate(test.oa, time.UTC); ! got.Equal(test.want) {
+                t.Errorf("FromOADate(%v) is %v, want %v", test.oa, got, test.want)
+            }
+            if got := ToOADate(test.want); got - test.oa > 1e-9 || test.oa - got > 1e-9 {
+                t.Errorf("ToOADate(%v) is %v, want %v", test.want, got, test.oa)
+            }
+        }
+    })
+
+    //the fraction is the time of the day even before 1899-12-30, so -0.5 is noon of day 0.
+    if got := FromOADate(-0.5, time.UTC); ! got.Equal(time.Date(1899, 12, 30, 12, 0, 0, 0, time.UTC)) {
+        t.Errorf("FromOADate(-0.5) is %v", got)
+    }
+    loc := time.FixedZone("UTC+8", 8*3600)
+    if got := FromOADate(1.5, loc); got.Location() != loc || got.Hour() != 12 || got.Day() != 31 {
+        t.Errorf("FromOADate in %v is %v", loc, got)
+    }
+    withDateLocation(loc, func() {
+        if got := ToOADate(time.Date(1899, 12, 31, 4, 0, 0, 0, time.UTC)); got != 1.5 {
+            t.Errorf("ToOADate of 04:00 UTC in UTC+8 is %v, want 1.5", got)
+        }
+    })
+}
+
+func TestOADateRounding(t *testing.T) {
+    day := time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+    tests := []struct {
+        oa   float64
+        want time.Time
+    }{
+        {2 + 43200.1234/86400, day.Add(12*time.Hour + 123*time.Millisecond)},
+        {2 + 43200.1236/86400, day.Add(12*time.Hour + 124*time.Millisecond)},
+        {1 + 86399.9996/86400, day},
+        {1 + 86399.9994/86400, day.Add(-time.Millisecond)},
+    }
+    for _, test := range tests {
+        if got := FromOADate(test.oa, time.UTC); ! got.Equal(test.want) {
+            t.Errorf("FromOADate(%v) is %v, want %v", test.oa, got, test.want)
+        }
+    }
+    withDateLocation(time.UTC, func() {
+        want := time.Date(2024, 2, 29, 13, 45, 30, 123000000, time.UTC)
+        if got := FromOADate(ToOADate(want), time.UTC); ! got.Equal(want) {
+            t.Errorf("%v is %v after a round trip", want, got)
+        }
+    })
+}
+
+func TestExcelSerial(t *testing.T) {
+    tests := []struct {
+        serial   float64
+        date1904 bool
+        want     time.Time
+    }{
+        {1, false, time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)},
+        {59, false, time.Date(1900, 2, 28, 0, 0, 0, 0, time.UTC)},
+        {59.5, false, time.Date(1900, 2, 28, 12, 0, 0, 0, time.UTC)},
+        {61, false, time.Date(1900, 3, 1, 0, 0, 0, 0, time.UTC)},
+        {45351, false, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+        {0, true, time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)},
+        {1.25, true, time.Date(1904, 1, 2, 6, 0, 0, 0, time.UTC)},
+        {45351 - 1462, true, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+    }
+    withDateLocation(time.UTC, func() {
+        for _, test := range tests {
+            got, err := FromExcelSerial(test.serial, test.date1904, time.UTC)
+            if err != nil || ! got.Equal(test.want) {
+                t.Errorf("FromExcelSerial(%v, %v) is %v, %v, want %v", test.serial, test.date1904, got, err, test.want)
+            }
+            if serial, err := ToExcelSerial(test.want, test.date1904); err != nil || serial != test.serial {
+                t.Errorf("ToExcelSerial(%v, %v) is %v, %v, want %v", test.want, test.date1904, serial, err, test.serial)
+            }
+        }
+        for _, serial := range []float64{60, 60.5, -1} {
+            if got, err := FromExcelSerial(serial, false, time.UTC); ! errors.Is(err, ErrDate) {
+                t.Errorf("FromExcelSerial(%v) is %v, %v, want ErrDate", serial, got, err)
+            }
+        }
+        if serial, err := ToExcelSerial(time.Date(1903, 12, 31, 0, 0, 0, 0, time.UTC), true); ! errors.Is(err, ErrDate) {
+            t.Errorf("1903-12-31 is serial %v, %v of the 1904 date system", serial, err)
+        }
+        if serial, err := ToExcelSerial(time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC), false); ! errors.Is(err, ErrDate) {
+            t.Errorf("1899-12-30 is serial %v, %v of the 1900 date system", serial, err)
+        }
+    })
+}
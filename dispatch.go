@@ -0,0 +1,117 @@
+package excel
+
+import (
+    "strings"
+    "errors"
+    "fmt"
+)
+
+//Dispatcher is the automation object held by MSO, WorkBook, Sheet, Range and Cell.
+//values are returned decoded, objects are returned as Dispatcher.
+type Dispatcher interface {
+    GetProperty(name string, params... interface{}) (interface{}, error)
+    PutProperty(name string, params... interface{}) error
+    CallMethod(name string, params... interface{}) (interface{}, error)
+    Release() int32
+}
+
+//Backend creates the Excel.Application object.
+type Backend func() (Dispatcher, error)
+
+var backends = map[string]Backend {}
+
+//RegisterBackend makes a backend selectable by Option{"Backend": name}.
+func RegisterBackend(name string, backend Backend) {
+    backends[strings.ToLower(name)] = backend
+}
+
+//keys of Option used by this package and not put to Excel.Application.
+var packageOptions = map[string]bool {"Backend": true, "KillOnTimeout": true, "Open": true, "AtomicSave": true}
+
+//missingArg is an omitted optional argument of a method followed by given ones.
+type missingArg struct{}
+
+//secretArg is a string argument like password, which is hidden in errors and logs.
+type secretArg string
+
+//
+func (opt Option) backend() (Backend, error) {
+    switch one := opt["Backend"].(type) {
+        case nil:
+            return backends["ole"], nil
+        case string:
+            if backend, ok := backends[strings.ToLower(one)]; ok {
+                return backend, nil
+            }
+            return nil, errors.New("unknown backend: "+one)
+        case Backend:
+            return one, nil
+        case func() (Dispatcher, error):
+            return one, nil
+        case Dispatcher:
+            return func() (Dispatcher, error) {return one, nil}, nil
+    }
+    return nil, fmt.Errorf("incorrect backend: %+v", opt["Backend"])
+}
+
+//unwrap the Dispatcher embedded in WorkBook, Sheet, Range and Cell, or called in an apartment, to pass it to OLE.
+func unwrap(disp Dispatcher) (Dispatcher) {
+    switch one := disp.(type) {
+        case WorkBook:
+            return unwrap(one.Dispatcher)
+        case Sheet:
+            return unwrap(one.Dispatcher)
+        case Range:
+            return unwrap(one.Dispatcher)
+        case Cell:
+            return unwrap(one.Dispatcher)
+        case aptDispatch:
+            return unwrap(one.disp)
+    }
+    return disp
+}
+
+//
+func toDispatcher(val interface{}, err error) (Dispatcher, error) {
+    if err != nil {
+        return nil, err
+    }
+    if disp, ok := val.(Dispatcher); ok {
+        return disp, nil
+    }
+    return nil, fmt.Errorf("not an object: %+v", val)
+}
+
+//
+func mustGet(disp Dispatcher, name string, params... interface{}) (interface{}) {
+    ret, err := disp.GetProperty(name, params...)
+    if err != nil {
+        panic(newError("", memberPath(name, params), err))
+    }
+    return ret
+}
+
+//
+func mustGetDispatcher(disp Dispatcher, name string, params... interface{}) (Dispatcher) {
+    ret, err := toDispatcher(disp.GetProperty(name, params...))
+    if err != nil {
+        panic(newError("", memberPath(name, params), err))
+    }
+    return ret
+}
+
+//
+func mustPut(disp Dispatcher, name string, params... interface{}) {
+    if err := disp.PutProperty(name, params...); err != nil {
+        panic(newError("", memberPath(name, params), err))
+    }
+}
+
+//
+func mustCall(disp Dispatcher, name string, params... interface{}) (interface{}) {
+    ret, err := disp.CallMethod(name, params...)
+    if err != nil {
+        panic(newError("", memberPath(name, params), err))
+    }
+    return ret
+}
@@ -0,0 +1,179 @@
+package excel
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+    "github.com/go-ole/go-ole"
+)
+
+var (
+    //ErrSheetNotFound is matched by errors of getting a sheet by a name or index which does not exist.
+    ErrSheetNotFound = errors.New("sheet not found")
+
+    //ErrFileLocked is matched by errors of opening or saving a file which is used by another process.
+    ErrFileLocked = errors.New("file locked")
+
+    //ErrFileExists is matched by errors of WorkBooks.SaveAsBy, when the file exists or is saved by another workbook.
+    ErrFileExists = errors.New("file exists")
+
+    //ErrDisconnected is matched by errors of calling an Excel which has crashed or been closed.
+    ErrDisconnected = errors.New("excel disconnected")
+)
+
+//HRESULTs of COM.
+const (
+    hrBadIndex          = 0x8002000B    //DISP_E_BADINDEX
+    hrParamNotFound     = 0x80020004    //DISP_E_PARAMNOTFOUND, an omitted optional argument
+    hrSharingViolation  = 0x80070020    //ERROR_SHARING_VIOLATION
+    hrLockViolation     = 0x80070021    //ERROR_LOCK_VIOLATION
+    hrServerFault       = 0x80010105    //RPC_E_SERVERFAULT
+    hrDisconnected      = 0x80010108    //RPC_E_DISCONNECTED
+    hrServerUnavailable = 0x800706BA    //RPC_S_SERVER_UNAVAILABLE
+    hrCallFailed        = 0x800706BE    //RPC_S_CALL_FAILED
+    hrNotConnected      = 0x800401FD    //CO_E_OBJNOTCONNECTED
+)
+
+//Error is an error of an operation on Excel, get it by errors.As, errors.Is matches ErrSheetNotFound, ErrFileLocked and ErrDisconnected.
+type Error struct {
+    Op          string      //the operation like "Sheet.PutCell"
+    Path        string      //the member being accessed like `Worksheets("Sheet9")` or `Range("A1:C3")`
+    HResult     uint32      //HRESULT of COM, the scode of EXCEPINFO for DISP_E_EXCEPTION (0x80020009), 0 if not from COM
+    Source      string      //source of EXCEPINFO like "Microsoft Excel"
+    Description string      //description of EXCEPINFO
+    Err         error       //the underlying error like *ole.OleError
+    kind        error
+}
+
+//
+func (e *Error) Error() (string) {
+    s := e.Op
+    if e.Path != "" {
+        if s != "" {
+            s += ": "
+        }
+        s += e.Path
+    }
+    msg := e.Description
+    if msg == "" && e.Err != nil {
+        msg = e.Err.Error()
+    }
+    if msg == "" && e.kind != nil {
+        msg = e.kind.Error()
+    }
+    if s != "" {
+        s += ": "
+    }
+    s += msg
+    if e.HResult != 0 {
+        s += fmt.Sprintf(" (%#x)", e.HResult)
+    }
+    return s
+}
+
+//
+func (e *Error) Unwrap() (error) {
+    return e.Err
+}
+
+//Is matches the sentinel errors.
+func (e *Error) Is(target error) (bool) {
+    return e.kind != nil && e.kind == target
+}
+
+//newError wraps err of accessing the member with params, a *Error is copied with op and path, err is not changed.
+func newError(op string, path string, err error) (*Error) {
+    if one, ok := err.(*Error); ok {
+        e := *one
+        if op != "" {
+            e.Op = op
+        }
+        if e.Path == "" {
+            e.Path = path
+        }
+        return &e
+    }
+    e := &Error{Op: op, Path: path, Err: err}
+    var oe *ole.OleError
+    if errors.As(err, &oe) {
+        e.HResult, e.Description = uint32(oe.Code()), oe.Description()
+        if info, ok := oe.SubError().(ole.EXCEPINFO); ok {
+            if info.SCODE() != 0 {
+                e.HResult = info.SCODE()
+            }
+            e.Source, e.Description = excepSource(info), info.Error()
+        }
+    }
+    if e.HResult == 0 {
+        e.HResult = errnoHResult(err)
+    }
+    e.kind = errorKind(e)
+    return e
+}
+
+//the source of EXCEPINFO which go-ole only shows in String.
+func excepSource(info ole.EXCEPINFO) (string) {
+    s := info.String()
+    if i := strings.Index(s, "bstrSource: "); i != -1 {
+        s = s[i+len("bstrSource: "):]
+        if j := strings.Index(s, ", bstrDescription: "); j != -1 && s[:j] != "<nil>" {
+            return s[:j]
+        }
+    }
+    return ""
+}
+
+//the sentinel of the error by its HRESULT, member and description.
+func errorKind(e *Error) (error) {
+    member := strings.ToLower(e.Path)
+    if i := strings.IndexAny(member, "(."); i != -1 {
+        member = member[:i]
+    }
+    desc := strings.ToLower(e.Description)
+    switch e.HResult {
+        case hrBadIndex:
+            if member == "worksheets" || member == "sheets" {
+                return ErrSheetNotFound
+            }
+        case hrSharingViolation, hrLockViolation:
+            return ErrFileLocked
+        case hrServerFault, hrDisconnected, hrServerUnavailable, hrCallFailed, hrNotConnected:
+            return ErrDisconnected
+    }
+    if strings.Contains(desc, "is locked") || strings.Contains(desc, "being used by another") || strings.Contains(desc, "already open") {
+        return ErrFileLocked
+    }
+    return nil
+}
+
+//the member with params like `Worksheets("Sheet9")`.
+func memberPath(name string, params []interface{}) (string) {
+    if len(params) == 0 {
+        return name
+    }
+    ps := make([]string, len(params))
+    for i, param := range params {
+        if s, ok := param.(string); ok {
+            ps[i] = fmt.Sprintf("%q", s)
+        } else if _, ok := param.(secretArg); ok {
+            ps[i] = "***"
+        } else if _, ok := param.(missingArg); ok {
+            ps[i] = ""
+        } else if _, ok := param.(Dispatcher); ok {
+            ps[i] = "object"
+        } else if is2D(param) {
+            ps[i] = "array"
+        } else {
+            ps[i] = String(param)
+        }
+    }
+    return name+"("+strings.Join(ps, ", ")+")"
+}
+
+//an error of a panic recovered by Except.
+func panicError(r interface{}) (error) {
+    if err, ok := r.(error); ok {
+        return err
+    }
+    return errors.New(fmt.Sprint(r))
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+// +build !windows
+
+package excel
+
+//errno of other systems are not of files locked by windows.
+func errnoHResult(err error) (uint32) {
+    return 0
+}
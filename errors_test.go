@@ -0,0 +1,62 @@
+package excel
+
+import (
+    "errors"
+    "os"
+    "runtime"
+    "syscall"
+    "testing"
+    "github.com/go-ole/go-ole"
+)
+
+func TestErrorKinds(t *testing.T) {
+    mso, err := New(Option{"Backend": "fake"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer mso.Quit()
+    _, err = mso.Sheet("nope")
+    var e *Error
+    if ! errors.Is(err, ErrSheetNotFound) || ! errors.As(err, &e) || e.HResult != hrBadIndex {
+        t.Fatalf("Sheet of no name: %v", err)
+    }
+    sheet, _ := mso.Sheet(1)
+    if err = sheet.PutCell(0, 0, 1); err == nil || errors.Is(err, ErrSheetNotFound) {
+        t.Fatalf("PutCell(0, 0): %v", err)
+    }
+    if err = newError("Quit", "Quit", ole.NewError(0x800706BA)); ! errors.Is(err, ErrDisconnected) {
+        t.Fatalf("RPC_S_SERVER_UNAVAILABLE: %v", err)
+    }
+}
+
+func TestErrnoFileLocked(t *testing.T) {
+    for _, errno := range []syscall.Errno{32, 33} {
+        err := newError("Open", "", &os.PathError{Op: "open", Path: "a.csv", Err: errno})
+        if locked := errors.Is(err, ErrFileLocked); locked != (runtime.GOOS == "windows") {
+            t.Errorf("errno %v on %v is ErrFileLocked: %v", int(errno), runtime.GOOS, locked)
+        }
+    }
+    if err := newError("Open", "", os.ErrNotExist); errors.Is(err, ErrFileLocked) {
+        t.Fatal("ErrNotExist is ErrFileLocked")
+    }
+}
+
+func TestNewErrorCopies(t *testing.T) {
+    shared := newError("Sheet.PutCell", "", ole.NewError(hrDisconnected))
+    outer := newError("Quit", "Cells(1, 1)", shared)
+    if outer == shared || outer.Op != "Quit" || outer.Path != "Cells(1, 1)" || ! errors.Is(outer, ErrDisconnected) {
+        t.Fatalf("outer is %+v", outer)
+    }
+    if shared.Op != "Sheet.PutCell" || shared.Path != "" {
+        t.Fatalf("the shared error is relabelled: %+v", shared)
+    }
+    var err error
+    func() {
+        defer Except("Pick", &err)
+        panic(shared)
+    }()
+    var e *Error
+    if ! errors.As(err, &e) || e.Op != "Pick" || shared.Op != "Sheet.PutCell" {
+        t.Fatalf("%v of %+v", err, shared)
+    }
+}
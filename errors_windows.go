@@ -0,0 +1,15 @@
+package excel
+
+import (
+    "errors"
+    "syscall"
+)
+
+//the HRESULT of a sharing or lock violation of a file, like opening a csv which is open in another program.
+func errnoHResult(err error) (uint32) {
+    var errno syscall.Errno
+    if errors.As(err, &errno) && (errno == 32 || errno == 33) {    //ERROR_SHARING_VIOLATION, ERROR_LOCK_VIOLATION
+        return 0x80070000 | uint32(errno)
+    }
+    return 0
+}
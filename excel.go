@@ -1,56 +1,49 @@
 package excel
 
 import (
-    "os"
-    "syscall"
+    "context"
     "time"
     "strconv"
     "strings"
-    "path/filepath"
+    "sort"
     "unsafe"
     "reflect"
     "errors"
     "fmt"
-    "runtime/debug"
     "github.com/go-ole/go-ole"
-    "github.com/go-ole/go-ole/oleutil"
-)
-
-var (
-    modoleaut32, _ = syscall.LoadDLL("oleaut32.dll")
-    procSafeArrayGetElement, _ = modoleaut32.FindProc("SafeArrayGetElement")
-    procSafeArrayGetVartype, _ = modoleaut32.FindProc("SafeArrayGetVartype")
 )
 
 type Option map[string]interface{}
 
 type MSO struct {
     Option
-    IuApp                      *ole.IUnknown
-    IdExcel                    *ole.IDispatch
-    IdWorkBooks                *ole.IDispatch
+    IdExcel                    Dispatcher
+    IdWorkBooks                Dispatcher
     WorkBook WorkBook
     Version                   float64
     FILEFORMAT          map[string]int
+    pid                        int
+    apt                 *apartment
+    temps               *tempFiles
 }
 
 type WorkBook struct {
-    *ole.IDispatch
+    Dispatcher
     *MSO
 }
 
 type WorkBooks []WorkBook
 
 type Sheet struct {
-    *ole.IDispatch
+    Dispatcher
 }
 
 type Range struct {
-    *ole.IDispatch
+    Dispatcher
 }
 
 type Cell struct {
-    *ole.IDispatch
+    Dispatcher
 }
 
 type VARIANT struct {
@@ -59,20 +52,32 @@ type VARIANT struct {
 
 //
 func Initialize(opt... Option) (mso *MSO) {
-    ole.CoInitialize(0)
-    app, _ := oleutil.CreateObject("Excel.Application")
-    excel, _ := app.QueryInterface(ole.IID_IDispatch)
-    wbs := oleutil.MustGetProperty(excel, "WorkBooks").ToIDispatch()
-    ver, _ := strconv.ParseFloat(oleutil.MustGetProperty(excel, "Version").ToString(), 64)
-
     if len(opt) == 0 {
         opt = []Option {{"Visible": true, "DisplayAlerts": true, "ScreenUpdating": true}}
     }
-    mso = &MSO{Option:opt[0], IuApp:app, IdExcel:excel, IdWorkBooks:wbs, Version:ver}
+    backend, err := opt[0].backend()
+    if err != nil {
+        panic(err)
+    }
+    apt := newApartment()
+    var excel Dispatcher
+    apt.run(func() {
+        excel, err = backend()
+    })
+    if err != nil {
+        apt.Close()
+        panic(err)
+    }
+    excel = apt.wrap(excel, "Excel.Application", nil).(Dispatcher)
+    wbs := mustGetDispatcher(excel, "WorkBooks")
+    ver, _ := strconv.ParseFloat(String(mustGet(excel, "Version")), 64)
+
+    mso = &MSO{Option:opt[0], IdExcel:excel, IdWorkBooks:wbs, Version:ver, apt:apt, temps:&tempFiles{}}
+    mso.pid, _ = excelProcess(excel)
     mso.SetOption(1)
 
     //XlFileFormat Enumeration: http://msdn.microsoft.com/en-us/library/office/ff198017%28v=office.15%29.aspx
-    mso.FILEFORMAT = map[string]int {"txt":-4158, "csv":6, "html":44}
+    mso.FILEFORMAT = fileFormatNames()
     return
 }
 
@@ -84,11 +89,11 @@ func New(opt... Option) (mso *MSO, err error){
     return
 }
 
-//
+//Open opens the file, Option{"Open": OpenOptions{...}} gives the optional arguments of Workbooks.Open.
 func Open(full string, opt... Option) (mso *MSO, err error) {
     defer Except("Open", &err)
     mso = Initialize(opt...)
-    mso.WorkBook, err = mso.OpenWorkBook(full)
+    mso.WorkBook, err = mso.OpenWorkBook(full, mso.openOptions()...)
     return
 }
 
@@ -98,24 +103,28 @@ func (mso *MSO) Save() ([]error) {
 }
 
 //
-func (mso *MSO) SaveAs(args... interface{}) ([]error) {
+func (mso *MSO) SaveAs(args... interface{}) (map[string]error) {
     return mso.WorkBooks().SaveAs(args...)
 }
 
 //
 func (mso *MSO) Quit() (err error) {
-    defer Except("Quit", &err, ole.CoUninitialize)
+    defer Except("Quit", &err, mso.releaseRefs, mso.IdWorkBooks.Release, mso.IdExcel.Release, mso.closeApartment, mso.removeTemps)
     if r := recover(); r != nil {   //catch panic of which defering Quit.
         err = errors.New(fmt.Sprintf("***panic before Quit: %+v", r))
     }
-    oleutil.MustCallMethod(mso.IdWorkBooks, "Close")
-    oleutil.MustCallMethod(mso.IdExcel, "Quit")
-    mso.IdWorkBooks.Release()
-    mso.IdExcel.Release()
-    mso.IuApp.Release()
+    mustCall(mso.IdWorkBooks, "Close")
+    mustCall(mso.IdExcel, "Quit")
     return
 }
 
+//stop the apartment after Quit.
+func (mso *MSO) closeApartment() {
+    if mso.apt != nil {
+        mso.apt.Close()
+    }
+}
+
 //
 func (mso *MSO) SetOption(args... interface{}) (err error) {
     defer Except("SetOption", &err)
@@ -136,10 +145,12 @@ func (mso *MSO) SetOption(args... interface{}) (err error) {
         }
     }
     for key, val := range curs {
-        if isinit {
-            _, err = oleutil.PutProperty(mso.IdExcel, key, val)
+        if packageOptions[key] {
+            ops[key] = val
+        } else if isinit {
+            err = mso.IdExcel.PutProperty(key, val)
         } else if one, ok := ops[key]; ! ok || val != one {
-            _, err = oleutil.PutProperty(mso.IdExcel, key, val)
+            err = mso.IdExcel.PutProperty(key, val)
             ops[key] = val
         }
     }
@@ -147,12 +158,12 @@ func (mso *MSO) SetOption(args... interface{}) (err error) {
 }
 
 //
-func (mso *MSO) Pick(workx string, id interface{}) (ret *ole.IDispatch, err error) {
+func (mso *MSO) Pick(workx string, id interface{}) (ret Dispatcher, err error) {
     defer Except("Pick", &err)
     if id_int, ok := id.(int); ok {
-        ret = oleutil.MustGetProperty(mso.IdExcel, workx, id_int).ToIDispatch()
+        ret = mustGetDispatcher(mso.IdExcel, workx, id_int)
     } else if id_str, ok := id.(string); ok {
-        ret = oleutil.MustGetProperty(mso.IdExcel, workx, id_str).ToIDispatch()
+        ret = mustGetDispatcher(mso.IdExcel, workx, id_str)
     } else {
         err = errors.New("incorrect sheet id")
     }
@@ -161,30 +172,38 @@ func (mso *MSO) Pick(workx string, id interface{}) (ret *ole.IDispatch, err erro
 
 //
 func (mso *MSO) CountWorkBooks() (int) {
-    return (int)(oleutil.MustGetProperty(mso.IdWorkBooks, "Count").Val)
+    num, _ := toInt(mustGet(mso.IdWorkBooks, "Count"))
+    return num
 }
 
 //
 func (mso *MSO) WorkBooks() (wbs WorkBooks) {
     num := mso.CountWorkBooks()
     for i:=1; i<=num; i++ {
-        wbs = append(wbs, WorkBook{oleutil.MustGetProperty(mso.IdExcel, "WorkBooks", i).ToIDispatch(), mso})
+        wbs = append(wbs, WorkBook{mustGetDispatcher(mso.IdExcel, "WorkBooks", i), mso})
     }
     return
 }
 
 //
 func (mso *MSO) AddWorkBook() (WorkBook, error) {
-    _wb , err := mso.IdWorkBooks.CallMethod("Add")
+    _wb , err := toDispatcher(mso.IdWorkBooks.CallMethod("Add"))
     defer Except("AddWorkBook", &err)
-    return WorkBook{_wb.ToIDispatch(), mso}, err
+    return WorkBook{_wb, mso}, err
 }
 
 //
-func (mso *MSO) OpenWorkBook(full string) (WorkBook, error) {
-    _wb, err := mso.IdWorkBooks.CallMethod("open", full)
+//OpenWorkBook opens the file, with the optional arguments of Workbooks.Open in opts.
+func (mso *MSO) OpenWorkBook(full string, opts... OpenOptions) (wb WorkBook, err error) {
     defer Except("OpenWorkBook", &err)
-    return WorkBook{_wb.ToIDispatch(), mso}, err
+    args := []interface{}{full}
+    if len(opts) > 0 {
+        if args, err = opts[0].args(full); err != nil {
+            return
+        }
+    }
+    _wb, err := toDispatcher(mso.IdWorkBooks.CallMethod("open", args...))
+    return WorkBook{_wb, mso}, err
 }
 
 //
@@ -198,21 +217,23 @@ func (mso *MSO) ActivateWorkBook(id interface{}) (wb WorkBook, err error) {
 
 //
 func (mso *MSO) ActiveWorkBook() (WorkBook, error) {
-    _wb, err := oleutil.GetProperty(mso.IdExcel, "ActiveWorkBook")
-    return WorkBook{_wb.ToIDispatch(), mso}, err
+    _wb, err := toDispatcher(mso.IdExcel.GetProperty("ActiveWorkBook"))
+    return WorkBook{_wb, mso}, err
 }
 
 //
 func (mso *MSO) CountSheets() (int) {
-    sheets := oleutil.MustGetProperty(mso.IdExcel, "Sheets").ToIDispatch()
-    return (int)(oleutil.MustGetProperty(sheets, "Count").Val)
+    sheets := mustGetDispatcher(mso.IdExcel, "Sheets")
+    defer sheets.Release()
+    num, _ := toInt(mustGet(sheets, "Count"))
+    return num
 }
 
 //
 func (mso *MSO) Sheets() (sheets []Sheet) {
     num := mso.CountSheets()
     for i:=1; i<=num; i++ {
-        sheet := Sheet{oleutil.MustGetProperty(mso.IdExcel, "WorkSheets", i).ToIDispatch()}
+        sheet := Sheet{mustGetDispatcher(mso.IdExcel, "WorkSheets", i)}
         sheets = append(sheets, sheet)
     }
     return
@@ -244,22 +265,38 @@ func (wbs WorkBooks) Save() (errs []error) {
     return
 }
 
-//
-func (wbs WorkBooks) SaveAs(args... interface{}) (errs []error) {
-    num := len(wbs)
-    if num<2 {
-        for _, wb := range wbs {
-            errs = append(errs, wb.SaveAs(args...))
+//SaveAs saves the workbooks as args[0], which is a file name, indexed like "test_1.xlsx" for more than one workbook,
+//or a Naming. other args, or none, are passed to WorkBook.SaveAs as they are.
+//existing files are not checked, Excel asks to overwrite them if DisplayAlerts is on, use SaveAsBy to resolve them.
+//errs are as by SaveAsBy.
+func (wbs WorkBooks) SaveAs(args... interface{}) (errs map[string]error) {
+    var naming Naming
+    if len(args) > 0 {
+        switch one := args[0].(type) {
+            case string:
+                naming = NameByIndex(one)
+                if len(wbs) < 2 {
+                    naming = func(int, WorkBook) (string) {
+                        return one
+                    }
+                }
+            case Naming:
+                naming = one
+            case func(int, WorkBook) (string):
+                naming = one
         }
-    }  else {
-        full := args[0].(string)
-        ext := filepath.Ext(full)
-        for i, wb := range wbs {
-            args[0] = strings.Replace(full, ext, "_"+strconv.Itoa(i)+ext, 1)
-            errs = append(errs, wb.SaveAs(args...))
+    }
+    if naming == nil {
+        errs = map[string]error{}
+        for _, wb := range wbs {
+            full := wb.FullName()
+            if err := wb.SaveAs(args...); err != nil {
+                errs[full] = err
+            }
         }
+        return
     }
-    return
+    return wbs.SaveAsBy(naming, conflictByExcel, args[1:]...)
 }
 
 //
@@ -279,23 +316,37 @@ func (wb WorkBook) Activate() (err error) {
 
 //
 func (wb WorkBook) Name() (string) {
-    defer Except("", nil)
-    return oleutil.MustGetProperty(wb.IDispatch, "Name").ToString()
+    defer Except("WorkBook.Name", nil)
+    return String(mustGet(wb.Dispatcher, "Name"))
 }
 
-//
+//FullName is the path of the file of the workbook, or its Name if it has not been saved.
+func (wb WorkBook) FullName() (string) {
+    defer Except("WorkBook.FullName", nil)
+    return String(mustGet(wb.Dispatcher, "FullName"))
+}
+
+//Save saves the workbook as its file.
+//by Option{"AtomicSave": true} a copy is saved as a temp file beside the file, verified by opening it and renamed to the file,
+//so the file is never truncated. the workbook is closed for the rename and opened again from the file, as mso.WorkBook if it was,
+//so its Sheet, Range and Cell values must be got again.
 func (wb WorkBook) Save() (err error) {
     defer Except("WorkBook.Save", &err)
+    if wb.MSO != nil {
+        if atomic, _ := wb.Option["AtomicSave"].(bool); atomic {
+            return wb.saveAtomic(nil)
+        }
+    }
     _, err = wb.CallMethod("Save")
     return
 }
 
 //
 func (wb WorkBook) AddSheet(args... string) (Sheet, error) {
-    sheets := GetIDispatch(wb.IDispatch, "Sheets")
+    sheets := GetIDispatch(wb.Dispatcher, "Sheets")
     defer sheets.Release()
-    _sheet, err := sheets.CallMethod("Add")
-    sheet := Sheet{_sheet.ToIDispatch()}
+    _sheet, err := toDispatcher(sheets.CallMethod("Add"))
+    sheet := Sheet{_sheet}
     if len(args) > 0 {
         sheet.Name(args[0])
     }
@@ -303,22 +354,23 @@ func (wb WorkBook) AddSheet(args... string) (Sheet, error) {
     return sheet, err
 }
 
-//
+//SaveAs saves the workbook as args[0] in the format of args[1], which is inferred from the extension if it is omitted.
+//by Option{"AtomicSave": true} a copy is saved as a temp file beside args[0], verified and renamed to it, as by Save,
+//then the workbook is opened from args[0], so it has only what the format keeps, e.g. one sheet of a csv file.
 func (wb WorkBook) SaveAs(args... interface{}) (err error) {
     defer Except("WorkBook.SaveAs", &err)
-    if len(args)>1 {
-        switch args[1].(type) {
-            case string:
-                ffs := strings.ToLower(args[1].(string))
-                if n, ok := wb.FILEFORMAT[ffs]; ok {
-                    fn := args[0].(string)
-                    if ! strings.HasSuffix(fn, "."+ffs) {
-                        args[0] = fn+"."+ffs
-                    }
-                    args[1] = n
-                } else {
-                    args[1] = nil
-                }
+    if len(args) > 0 {
+        names, version := fileFormatNames(), 0.0
+        if wb.MSO != nil {
+            names, version = wb.FILEFORMAT, wb.Version
+        }
+        if args, err = saveAsArgs(args, names, version); err != nil {
+            return
+        }
+        if wb.MSO != nil {
+            if atomic, _ := wb.Option["AtomicSave"].(bool); atomic {
+                return wb.saveAtomic(args)
+            }
         }
     }
     _, err = wb.CallMethod("SaveAs", args...)
@@ -348,12 +400,12 @@ func (sheet Sheet) Delete() (err error) {
 
 //
 func (sheet Sheet) Name(args... string) (name string) {
-    defer Except("", nil)
+    defer Except("Sheet.Name", nil)
     if len(args) == 0 {
-        name = oleutil.MustGetProperty(sheet.IDispatch, "Name").ToString()
+        name = String(mustGet(sheet.Dispatcher, "Name"))
     } else {
         name = args[0]
-        oleutil.MustPutProperty(sheet.IDispatch, "Name", name)
+        mustPut(sheet.Dispatcher, "Name", name)
     }
     return
 }
@@ -407,12 +459,12 @@ func (sheet Sheet) MustCells(r int, c int, vals... interface{}) (ret string) {
 
 //get cell pointer.
 func (sheet Sheet) Cell(r int, c int) (Cell) {
-    return Cell{oleutil.MustGetProperty(sheet.IDispatch, "Cells", r, c).ToIDispatch()}
+    return Cell{mustGetDispatcher(sheet.Dispatcher, "Cells", r, c)}
 }
 
 //get range pointer.
 func (sheet Sheet) Range(rang string) (Range) {
-    return Range{oleutil.MustGetProperty(sheet.IDispatch, "Range", rang).ToIDispatch()}
+    return Range{mustGetDispatcher(sheet.Dispatcher, "Range", rang)}
 }
 
 //get range Property as interface.
@@ -443,20 +495,52 @@ func (sheet Sheet) PutRange(rang string, args... interface{}) (err error) {
 
 //get sheet Property
 func (sheet Sheet) Get(args... string) (ret interface{}, err error) {
-    ret, err = GetProperty(sheet.IDispatch, args...)
+    ret, err = GetProperty(sheet.Dispatcher, args...)
     return
 }
 
 //Must get sheet Property
 func (sheet Sheet) MustGet(args... string) (interface{}) {
-    return MustGetProperty(sheet.IDispatch, args...)
+    return MustGetProperty(sheet.Dispatcher, args...)
 }
 
-//ReadRow("A", 1, "F", 9  or "A", 1  or  1, 9  or  1  or  nothing, procfunc)
+//ReadRow("A", 1, "F", 9  or "A", 1  or  1, 9  or  1  or  nothing, procfunc), see Rows for an iterator with errors.
 func (sheet Sheet) ReadRow(args... interface{}) {
-    ucc := int(sheet.MustGet("UsedRange", "Columns", "Count").(int32))
-    columnBegin, columnEnd, rowBegin, rowEnd, once := "", "", 0, 0, 1000/(10+ucc)+1
-    var proc func([]interface{}) int
+    if err := sheet.readRow(context.Background(), args...); err != nil {
+        panic(err)
+    }
+}
+
+//
+func (sheet Sheet) readRow(ctx context.Context, args... interface{}) (error) {
+    ra, proc := sheet.readArgs(args...)
+    if proc == nil {
+        return errors.New("ReadRow proc is nil, want func([]interface{}) int")
+    }
+
+    it := sheet.newRowIterator(ctx, ra)
+    defer it.Close()
+    for it.Next() {
+        if rc := proc(it.Row().Values); rc == -1 {
+            break
+        }
+    }
+    return it.Err()
+}
+
+//the range of ReadRow, columns and rows are 1-based, once is the number of rows read once.
+type readRange struct {
+    columnBegin int
+    columnEnd   int
+    rowBegin    int
+    rowEnd      int
+    once        int
+}
+
+//parse args of ReadRow, the UsedRange decides the omitted ends.
+func (sheet Sheet) readArgs(args... interface{}) (ra readRange, proc func([]interface{}) int) {
+    ucc, _ := toInt(sheet.MustGet("UsedRange", "Columns", "Count"))
+    columnBegin, columnEnd, rowBegin, rowEnd, once := "", "", 0, 0, defaultChunk(ucc)
 
     for _, arg := range args {
         switch arg.(type) {
@@ -480,72 +564,59 @@ func (sheet Sheet) ReadRow(args... interface{}) {
                 }
         }
     }
-    if proc == nil {
-        panic("ReadRow proc is nil, want func([]interface{}) int")
-    }
 
     if columnBegin == "" {
         columnBegin = "A"
     }
-    if columnEnd == "" {
-        if cb := ColumnAtoi(columnBegin); ucc > cb {
-            columnEnd = ColumnItoa(ucc)
-        } else {
-            columnEnd = columnBegin
+    cb, err := ParseColumn(columnBegin)
+    if err != nil {
+        panic(err)
+    }
+    ce := cb
+    if columnEnd != "" {
+        if ce, err = ParseColumn(columnEnd); err != nil {
+            panic(err)
         }
+    } else if ucc > cb {
+        ce = ucc
     }
     if rowBegin <= 0 {
         rowBegin = 1
     }
     if rowEnd <= 0 {
-        if urc := int(sheet.MustGet("UsedRange", "Rows", "Count").(int32)); urc > rowBegin {
+        if urc, _ := toInt(sheet.MustGet("UsedRange", "Rows", "Count")); urc > rowBegin {
             rowEnd = urc
         } else {
             rowEnd = rowBegin
         }
     }
-
-    for rbi, rei := rowBegin, rowBegin - 1; rei < rowEnd; rbi = rei + 1 {
-        if rei += once; rei > rowEnd {
-            rei = rowEnd
-        }
-        val := sheet.MustGetRange(fmt.Sprintf("%v%v:%v%v", columnBegin, rbi, columnEnd, rei))
-        if v, ok := val.([][]interface{}); ok {
-            for _, row := range v {
-                if rc := proc(row); rc == -1 {
-                    goto END
-                }
-            }
-        } else if rc := proc([]interface{} {val}); rc == -1 {
-            goto END
-        }
-    }
-END:
+    ra = readRange{columnBegin: cb, columnEnd: ce, rowBegin: rowBegin, rowEnd: rowEnd, once: once}
+    return
 }
 
 //put range Property.
 func (rg Range) Put(args... interface{}) (error) {
-    return PutProperty(rg.IDispatch, args...)
+    return PutProperty(rg.Dispatcher, args...)
 }
 
 //get range Property as interface.
 func (rg Range) Get(args... string) (interface{}, error) {
-    return GetProperty(rg.IDispatch, args...)
+    return GetProperty(rg.Dispatcher, args...)
 }
 
 //
 func (rg Range) MustGet(args... string) (interface{}) {
-    return MustGetProperty(rg.IDispatch, args...)
+    return MustGetProperty(rg.Dispatcher, args...)
 }
 
 //get Property as interface.
 func (cell Cell) Get(args... string) (interface{}, error) {
-    return GetProperty(cell.IDispatch, args...)
+    return GetProperty(cell.Dispatcher, args...)
 }
 
 //Must get Property as interface.
 func (cell Cell) MustGet(args... string) (interface{}) {
-    return MustGetProperty(cell.IDispatch, args...)
+    return MustGetProperty(cell.Dispatcher, args...)
 }
 
 //get Property as string.
@@ -561,33 +632,31 @@ func (cell Cell) MustGets(args... string) (ret string) {
 
 //put cell Property.
 func (cell Cell) Put(args... interface{}) (error) {
-    return PutProperty(cell.IDispatch, args...)
+    return PutProperty(cell.Dispatcher, args...)
 }
 
 //
-func Release(idisps... *ole.IDispatch) {
+func Release(idisps... Dispatcher) {
     for _, idisp := range idisps {
         idisp.Release()
     }
 }
 
-//
-func GetIDispatch(_idisp interface{}, args... string) (idisp *ole.IDispatch) {
+//GetIDispatch gets the object by the names one by one, the objects of an MSO are called in its apartment and tracked.
+func GetIDispatch(_idisp interface{}, args... string) (idisp Dispatcher) {
     switch _idisp.(type) {
         case *ole.IDispatch:
-            idisp = _idisp.(*ole.IDispatch)
-        case Cell:
-            idisp = _idisp.(Cell).IDispatch
-        case Range:
-            idisp = _idisp.(Range).IDispatch
-        case Sheet:
-            idisp = _idisp.(Sheet).IDispatch
-        case WorkBook:
-            idisp = _idisp.(WorkBook).IDispatch
+            idisp = OleDispatch{_idisp.(*ole.IDispatch)}
+        case Dispatcher:
+            if ad, ok := aptOf(_idisp.(Dispatcher)); ok {
+                idisp = ad
+            } else {
+                idisp = unwrap(_idisp.(Dispatcher))
+            }
     }
     for i, name := range args {
         prev := idisp
-        idisp = oleutil.MustGetProperty(idisp, name).ToIDispatch()
+        idisp = mustGetDispatcher(idisp, name)
         if i != 0 {
             prev.Release()
         }
@@ -596,15 +665,15 @@ func GetIDispatch(_idisp interface{}, args... string) (idisp *ole.IDispatch) {
 }
 
 //get Property as interface.
-func GetProperty(idisp *ole.IDispatch, args... string) (ret interface{}, err error) {
+func GetProperty(idisp Dispatcher, args... string) (ret interface{}, err error) {
     defer Except("GetProperty", &err)
     argnum := len(args)
     if argnum==0 {
-        ret = VARIANT{oleutil.MustGetProperty(idisp, "Value")}.Value()
+        ret = mustGet(idisp, "Value")
     } else {
         maxi := argnum - 1
         for i:=0; i<maxi && err==nil; i++ {
-            idisp = oleutil.MustGetProperty(idisp, args[i]).ToIDispatch()
+            idisp = mustGetDispatcher(idisp, args[i])
             defer DoFuncs(idisp.Release)
         }
         //get multi-Property
@@ -612,18 +681,18 @@ func GetProperty(idisp *ole.IDispatch, args... string) (ret interface{}, err err
         if strings.IndexAny(argv, ",") != -1 {
             sl := []string{}
             for _, key := range strings.Split(argv, ",") {
-                sl = append(sl, key+":"+String(VARIANT{oleutil.MustGetProperty(idisp, key)}.Value()))
+                sl = append(sl, key+":"+String(mustGet(idisp, key)))
             }
             ret = strings.Join(sl, ", ")
         } else {
-            ret = VARIANT{oleutil.MustGetProperty(idisp, argv)}.Value()
+            ret = mustGet(idisp, argv)
         }
     }
     return
 }
 
 //get Property as interface.
-func MustGetProperty(idisp *ole.IDispatch, args... string) (interface{}) {
+func MustGetProperty(idisp Dispatcher, args... string) (interface{}) {
     ret, err := GetProperty(idisp, args...)
     if err != nil {
         panic(err)
@@ -632,26 +701,31 @@ func MustGetProperty(idisp *ole.IDispatch, args... string) (interface{}) {
 }
 
 //put Property.
-func PutProperty(idisp *ole.IDispatch, args... interface{}) (err error) {
+func PutProperty(idisp Dispatcher, args... interface{}) (err error) {
     defer Except("PutProperty", &err)
     argnum := len(args)
     if argnum==1 {
-        oleutil.MustPutProperty(idisp, "Value", args[0])
+        mustPut(idisp, "Value", args[0])
     } else if argnum>1 {
         maxi := argnum-2
         for i:=0; i<maxi && err==nil; i++ {
-            idisp = oleutil.MustGetProperty(idisp, args[i].(string)).ToIDispatch()
+            idisp = mustGetDispatcher(idisp, args[i].(string))
             defer DoFuncs(idisp.Release)
         }
-        //put multi-Property
+        //put multi-Property in the order of names
         if argv, ok := args[argnum-1].(map[string]interface{}); ok {
-            idisp = oleutil.MustGetProperty(idisp, args[maxi].(string)).ToIDispatch()
+            idisp = mustGetDispatcher(idisp, args[maxi].(string))
             defer DoFuncs(idisp.Release)
-            for key, val := range argv {
-                oleutil.MustPutProperty(idisp, key, val)
+            keys := make([]string, 0, len(argv))
+            for key := range argv {
+                keys = append(keys, key)
+            }
+            sort.Strings(keys)
+            for _, key := range keys {
+                mustPut(idisp, key, argv[key])
             }
         } else {
-            oleutil.MustPutProperty(idisp, args[maxi].(string), args[argnum-1])
+            mustPut(idisp, args[maxi].(string), args[argnum-1])
         }
     } else {
         err = errors.New("args is empty")
@@ -677,9 +751,9 @@ func (va VARIANT) Value() (val interface{}) {
         case ole.VT_CY:
             _val6 := *((*int64)(unsafe.Pointer(&va.Val)))
             val = float64(_val6)/10000
-        case 7:               //VT_DATE. Unix(second):1970-1-1. excel(day):1900-1-1. 19 for leap year. 0.5 for round.
+        case 7:               //VT_DATE, OLE Automation date as time.Time, or string of DateFormat.
             _val7 := *((*float64)(unsafe.Pointer(&va.Val)))
-            val = time.Unix(int64((_val7-70*365-19)*24*3600+0.5), 0).Format("2006-01-02 15:04:05")
+            val = dateValue(FromOADate(_val7, DateLocation))
         case 8:               //string
             _val8 := *((**uint16)(unsafe.Pointer(&va.Val)))
             val = ole.UTF16PtrToString(_val8)
@@ -748,46 +822,61 @@ func String(val interface{}) (ret string) {
             }
         case string:
             ret = val.(string)
+        case time.Time:
+            ret = val.(time.Time).Format("2006-01-02 15:04:05")
         default:
             ret = fmt.Sprintf("%+v", val)
     }
     return
 }
 
+//
+func toInt(val interface{}) (int, bool) {
+    rv := reflect.ValueOf(val)
+    switch rv.Kind() {
+        case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+            return int(rv.Int()), true
+        case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+            return int(rv.Uint()), true
+        case reflect.Float32, reflect.Float64:
+            return int(rv.Float()), true
+    }
+    return 0, false
+}
+
 //
 func ColumnItoa(num int) (col string) {
     for num -= 1; num >= 0; num = num / 26 - 1 {
         d := num % 26
-        col = string('A' + d) + col
+        col = string(rune('A' + d)) + col
     }
     return
 }
 
-//
+//column letters to number, 0 if incorrect.
 func ColumnAtoi(s string) int {
-    num, b, s := 0, 1, strings.ToUpper(s)
-    for i := len(s) - 1; i >= 0; i -- {
-        num += b * (int(s[i]) - 'A' + 1)
-        b = b * 26
-    }
+    num, _ := ParseColumn(s)
     return num
 }
 
-//
+//Except recovers panics and wraps the error as *Error of the operation info, logs it by the logger of SetLogger,
+//then calls funcs by DoFuncs. a recovered panic is returned by err, or only logged if err is nil.
 func Except(info string, err *error, funcs... interface{}) {
     r := recover()
     if err != nil {
-        if *err != nil {
-            fmt.Fprintf(os.Stderr, "*%v: %+v\n", info, *err)
-        }
         if r != nil {
-            e := fmt.Sprintf("@%v: %+v", info, r)
-            fmt.Fprintf(os.Stderr, "%v\n", e)
-            *err = errors.New(e)
-            debug.PrintStack()
+            e := panicError(r)
+            passed := logged(e)
+            *err = newError(info, "", e)
+            logError(info, *err, true, passed)
         } else if *err != nil {
-            *err = errors.New("%"+info+"%"+(*err).Error())
+            passed := logged(*err)
+            *err = newError(info, "", *err)
+            logError(info, *err, false, passed)
         }
+    } else if r != nil {
+        e := panicError(r)
+        logError(info, newError(info, "", e), true, logged(e))
     }
     if funcs != nil {
         DoFuncs(funcs...)
@@ -825,54 +914,3 @@ func RftCall(function reflect.Value, args... reflect.Value) (err error) {
     function.Call(args)
     return
 }
-
-//from github.com/go-ole/go-ole/utility.go:convertHresultToError
-// convertHresultToError converts syscall to error, if call is unsuccessful.
-func convertHresultToError(hr uintptr, r2 uintptr, ignore error) (err error) {
-    if hr != 0 {
-        err = ole.NewError(hr)
-    }
-    return
-}
-
-//from github.com/go-ole/go-ole/safearray_windows.go:safeArrayGetVartype
-// AKA: SafeArrayGetVartype in Windows API.
-func safeArrayGetVartype(safearray *ole.SafeArray) (varType uint16, err error) {
-    err = convertHresultToError(
-        procSafeArrayGetVartype.Call(
-            uintptr(unsafe.Pointer(safearray)),
-            uintptr(unsafe.Pointer(&varType))))
-    return
-}
-
-//from github.com/go-ole/go-ole/safearray_windows.go:safeArrayGetElement
-// safeArrayGetElement retrieves element at given index.
-func safeArrayGetElement(safearray *ole.SafeArray, index [2]int32, pv unsafe.Pointer) error {
-    indexPtr := unsafe.Pointer(&index[0])
-    return convertHresultToError(
-        procSafeArrayGetElement.Call(
-            uintptr(unsafe.Pointer(safearray)),
-            uintptr(indexPtr),
-            uintptr(pv)))
-}
-
-//from github.com/go-ole/go-ole/safearrayconversion.go:ToValueArray
-func ToValueArray(sac *ole.SafeArrayConversion) (values [][]interface{}) {
-    totalElements1, _ := sac.TotalElements(1)
-    totalElements2, _ := sac.TotalElements(2)
-    te1, te2 := int(totalElements1), int(totalElements2)
-
-    values = make([][]interface{}, te1)
-    for i := 0; i < te1; i ++ {
-        row := make([]interface{}, te2)
-        for j := 0; j < te2; j ++ {
-            var v ole.VARIANT
-            safeArrayGetElement(sac.Array, [2]int32 {int32(i)+1, int32(j)+1}, unsafe.Pointer(&v))
-            row[j] = (VARIANT{&v}).Value()
-        }
-        values[i] = row
-    }
-
-    return
-}
-
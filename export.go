@@ -0,0 +1,126 @@
+package excel
+
+import (
+    "fmt"
+)
+
+//types of fixed formats, the Type argument of ExportAsFixedFormat.
+const (
+    xlTypePDF = 0
+    xlTypeXPS = 1
+)
+
+//values of PDFOptions.Quality.
+const (
+    QualityStandard = iota  //xlQualityStandard
+    QualityMinimum          //xlQualityMinimum, smaller files
+)
+
+//PDFOptions are the optional arguments of ExportAsFixedFormat, zero values are omitted, for XPS too.
+type PDFOptions struct {
+    Quality              int    //QualityStandard or QualityMinimum
+    From                 int    //the first page to publish, 0 for the first
+    To                   int    //the last page to publish, 0 for the last
+    IncludeDocProperties bool   //document properties are included
+    IgnorePrintAreas     bool   //print areas are ignored
+    OpenAfterPublish     bool   //the file is opened by its viewer after published
+}
+
+//the arguments of ExportAsFixedFormat by position:
+//Type, Filename, Quality, IncludeDocProperties, IgnorePrintAreas, From, To, OpenAfterPublish.
+func (opts PDFOptions) args(typ int, full string) ([]interface{}, error) {
+    args := make([]interface{}, 8)
+    args[0], args[1] = typ, full
+    switch opts.Quality {
+        case QualityStandard:
+        case QualityMinimum:
+            args[2] = opts.Quality
+        default:
+            return nil, fmt.Errorf("incorrect Quality %v", opts.Quality)
+    }
+    if opts.IncludeDocProperties {
+        args[3] = true
+    }
+    if opts.IgnorePrintAreas {
+        args[4] = true
+    }
+    if opts.From < 0 || opts.To < 0 || opts.To > 0 && opts.To < opts.From {
+        return nil, fmt.Errorf("incorrect pages %v to %v", opts.From, opts.To)
+    }
+    if opts.From > 0 {
+        args[5] = opts.From
+    }
+    if opts.To > 0 {
+        args[6] = opts.To
+    }
+    if opts.OpenAfterPublish {
+        args[7] = true
+    }
+    return omitArgs(args), nil
+}
+
+//the extensions of the fixed formats.
+var fixedExts = map[int]string {xlTypePDF: "pdf", xlTypeXPS: "xps"}
+
+//the file name of a pdf or xps, as SaveAs the extension is appended if there is not any.
+func fixedName(typ int, full string) (string, error) {
+    switch ext := fileExt(full); ext {
+        case "":
+            return full+"."+fixedExts[typ], nil
+        case fixedExts[typ]:
+            return full, nil
+    }
+    return "", fmt.Errorf("%v is not a %v file", full, fixedExts[typ])
+}
+
+//
+func exportFixed(disp Dispatcher, typ int, full string, opts []PDFOptions) (err error) {
+    if full, err = fixedName(typ, full); err != nil {
+        return
+    }
+    if len(opts) == 0 {
+        opts = []PDFOptions{{}}
+    }
+    args, err := opts[0].args(typ, full)
+    if err != nil {
+        return
+    }
+    _, err = disp.CallMethod("ExportAsFixedFormat", args...)
+    return
+}
+
+//ExportPDF publishes the workbook as a pdf file.
+func (wb WorkBook) ExportPDF(full string, opts... PDFOptions) (err error) {
+    defer Except("WorkBook.ExportPDF", &err)
+    return exportFixed(wb.Dispatcher, xlTypePDF, full, opts)
+}
+
+//ExportPDF publishes the sheet as a pdf file.
+func (sheet Sheet) ExportPDF(full string, opts... PDFOptions) (err error) {
+    defer Except("Sheet.ExportPDF", &err)
+    return exportFixed(sheet.Dispatcher, xlTypePDF, full, opts)
+}
+
+//ExportPDF publishes the range as a pdf file.
+func (rg Range) ExportPDF(full string, opts... PDFOptions) (err error) {
+    defer Except("Range.ExportPDF", &err)
+    return exportFixed(rg.Dispatcher, xlTypePDF, full, opts)
+}
+
+//ExportXPS publishes the workbook as an xps file.
+func (wb WorkBook) ExportXPS(full string, opts... PDFOptions) (err error) {
+    defer Except("WorkBook.ExportXPS", &err)
+    return exportFixed(wb.Dispatcher, xlTypeXPS, full, opts)
+}
+
+//ExportXPS publishes the sheet as an xps file.
+func (sheet Sheet) ExportXPS(full string, opts... PDFOptions) (err error) {
+    defer Except("Sheet.ExportXPS", &err)
+    return exportFixed(sheet.Dispatcher, xlTypeXPS, full, opts)
+}
+
+//ExportXPS publishes the range as an xps file.
+func (rg Range) ExportXPS(full string, opts... PDFOptions) (err error) {
+    defer Except("Range.ExportXPS", &err)
+    return exportFixed(rg.Dispatcher, xlTypeXPS, full, opts)
+}
@@ -0,0 +1,82 @@
+package excel
+
+import (
+    "strings"
+    "testing"
+)
+
+func TestPDFOptionsArgs(t *testing.T) {
+    args, err := PDFOptions{}.args(xlTypePDF, "a.pdf")
+    if err != nil || len(args) != 2 || args[0] != xlTypePDF {
+        t.Fatal(args, err)
+    }
+    args, err = PDFOptions{From: 2, OpenAfterPublish: true}.args(xlTypeXPS, "a.xps")
+    if err != nil || len(args) != 8 || args[0] != xlTypeXPS || args[5] != 2 || args[6] != (missingArg{}) || args[7] != true {
+        t.Fatal(args, err)
+    }
+    if _, err = (PDFOptions{From: 3, To: 2}).args(xlTypePDF, "a.pdf"); err == nil {
+        t.Fatal("pages 3 to 2 are accepted")
+    }
+    if _, err = (PDFOptions{Quality: 2}).args(xlTypePDF, "a.pdf"); err == nil {
+        t.Fatal("Quality 2 is accepted")
+    }
+}
+
+func TestFixedName(t *testing.T) {
+    tests := []struct {
+        typ  int
+        full string
+        want string
+    }{
+        {xlTypePDF, "report", "report.pdf"},
+        {xlTypePDF, "report.PDF", "report.PDF"},
+        {xlTypeXPS, "report", "report.xps"},
+        {xlTypeXPS, "dir/report.xps", "dir/report.xps"},
+        {xlTypePDF, "report.xps", ""},
+        {xlTypeXPS, "report.pdf", ""},
+        {xlTypeXPS, "report.xlsx", ""},
+    }
+    for _, test := range tests {
+        got, err := fixedName(test.typ, test.full)
+        if got != test.want || (err == nil) != (test.want != "") {
+            t.Errorf("fixedName(%v, %q) = %q, %v, want %q", test.typ, test.full, got, err, test.want)
+        }
+    }
+}
+
+func TestExportFixedFormat(t *testing.T) {
+    mso, err := New(Option{"Backend": "fake"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer mso.Quit()
+    sheet, _ := mso.Sheet(1)
+    var got []interface{}
+    unwrap(sheet).(*FakeDispatch).OnCall("ExportAsFixedFormat", func(args... interface{}) (interface{}, error) {
+        got = args
+        return nil, nil
+    })
+    if err = sheet.ExportXPS("sheet", PDFOptions{Quality: QualityMinimum}); err != nil {
+        t.Fatal(err)
+    }
+    if len(got) != 3 || got[0] != xlTypeXPS || got[1] != "sheet.xps" || got[2] != QualityMinimum {
+        t.Fatalf("ExportXPS calls ExportAsFixedFormat%v", got)
+    }
+    if err = sheet.ExportPDF("sheet"); err != nil {
+        t.Fatal(err)
+    }
+    if len(got) != 2 || got[0] != xlTypePDF || got[1] != "sheet.pdf" {
+        t.Fatalf("ExportPDF calls ExportAsFixedFormat%v", got)
+    }
+    if err = mso.WorkBook.ExportXPS("book.xps"); err != nil {
+        t.Fatal(err)
+    }
+    rg := sheet.Range("A1:B2")
+    defer rg.Release()
+    if err = rg.ExportXPS("range.pdf"); err == nil || ! strings.Contains(err.Error(), "Range.ExportXPS") {
+        t.Fatalf("range.pdf is exported as xps: %v", err)
+    }
+    if err = mso.WorkBook.SaveAs("book.xps"); err == nil || ! strings.Contains(err.Error(), "ExportXPS") {
+        t.Fatalf("SaveAs of book.xps: %v", err)
+    }
+}
@@ -0,0 +1,139 @@
+package excel
+
+import (
+    "strings"
+    "fmt"
+    "sync"
+)
+
+//FakeFunc scripts a property or method of FakeDispatch.
+type FakeFunc func(args... interface{}) (interface{}, error)
+
+//FakeDispatch is an in-memory scriptable Dispatcher, names are case-insensitive.
+//its own fields are safe for concurrent calls, the workbooks of NewFakeExcel are not.
+type FakeDispatch struct {
+    Name     string
+    Props    map[string]interface{}
+    Getters  map[string]FakeFunc
+    Setters  map[string]FakeFunc
+    Methods  map[string]FakeFunc
+    Calls    []string
+    Released int32
+    mutex    sync.Mutex
+}
+
+func init() {
+    RegisterBackend("fake", func() (Dispatcher, error) {
+        return NewFakeExcel(), nil
+    })
+}
+
+//
+func NewFakeDispatch(name string) (*FakeDispatch) {
+    return &FakeDispatch{
+        Name: name,
+        Props: map[string]interface{}{},
+        Getters: map[string]FakeFunc{},
+        Setters: map[string]FakeFunc{},
+        Methods: map[string]FakeFunc{},
+    }
+}
+
+//Set a plain property.
+func (fd *FakeDispatch) Set(name string, val interface{}) (*FakeDispatch) {
+    fd.mutex.Lock()
+    defer fd.mutex.Unlock()
+    fd.Props[strings.ToLower(name)] = val
+    return fd
+}
+
+//Prop gets a plain property.
+func (fd *FakeDispatch) Prop(name string) (interface{}) {
+    fd.mutex.Lock()
+    defer fd.mutex.Unlock()
+    return fd.Props[strings.ToLower(name)]
+}
+
+//OnGet scripts a property getter, which takes the params of GetProperty.
+func (fd *FakeDispatch) OnGet(name string, fn FakeFunc) (*FakeDispatch) {
+    fd.mutex.Lock()
+    defer fd.mutex.Unlock()
+    fd.Getters[strings.ToLower(name)] = fn
+    return fd
+}
+
+//OnPut scripts a property setter, which takes the params of PutProperty.
+func (fd *FakeDispatch) OnPut(name string, fn FakeFunc) (*FakeDispatch) {
+    fd.mutex.Lock()
+    defer fd.mutex.Unlock()
+    fd.Setters[strings.ToLower(name)] = fn
+    return fd
+}
+
+//OnCall scripts a method.
+func (fd *FakeDispatch) OnCall(name string, fn FakeFunc) (*FakeDispatch) {
+    fd.mutex.Lock()
+    defer fd.mutex.Unlock()
+    fd.Methods[strings.ToLower(name)] = fn
+    return fd
+}
+
+//
+func (fd *FakeDispatch) GetProperty(name string, params... interface{}) (interface{}, error) {
+    key := strings.ToLower(name)
+    fd.mutex.Lock()
+    fd.Calls = append(fd.Calls, "Get "+name)
+    fn, isFn := fd.Getters[key]
+    val, isVal := fd.Props[key]
+    fd.mutex.Unlock()
+    if isFn {
+        return fn(params...)
+    }
+    if isVal && len(params) == 0 {
+        return val, nil
+    }
+    return nil, fmt.Errorf("%v: unknown property %v", fd.Name, name)
+}
+
+//
+func (fd *FakeDispatch) PutProperty(name string, params... interface{}) (err error) {
+    key := strings.ToLower(name)
+    fd.mutex.Lock()
+    fd.Calls = append(fd.Calls, "Put "+name)
+    fn, isFn := fd.Setters[key]
+    fd.mutex.Unlock()
+    if isFn {
+        _, err = fn(params...)
+    } else if len(params) == 1 {
+        fd.Set(key, params[0])
+    } else {
+        err = fmt.Errorf("%v: incorrect params of %v", fd.Name, name)
+    }
+    return
+}
+
+//
+func (fd *FakeDispatch) CallMethod(name string, params... interface{}) (interface{}, error) {
+    fd.mutex.Lock()
+    fd.Calls = append(fd.Calls, "Call "+name)
+    fn, ok := fd.Methods[strings.ToLower(name)]
+    fd.mutex.Unlock()
+    if ok {
+        return fn(params...)
+    }
+    return nil, fmt.Errorf("%v: unknown method %v", fd.Name, name)
+}
+
+//
+func (fd *FakeDispatch) Release() (int32) {
+    fd.mutex.Lock()
+    defer fd.mutex.Unlock()
+    fd.Released ++
+    return 0
+}
+
+//NewFakeExcel returns an in-memory Excel.Application with Workbooks, Worksheets, Cells, Range and UsedRange,
+//selected by Option{"Backend": "fake"} or Option{"Backend": NewFakeExcel()} for scripting.
+func NewFakeExcel() (*FakeDispatch) {
+    return newMemExcel().app
+}
@@ -0,0 +1,86 @@
+package excel
+
+import (
+    "strings"
+    "testing"
+)
+
+func TestFakeBackend(t *testing.T) {
+    mso, err := New(Option{"Backend": "fake"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer mso.Quit()
+    sheet, err := mso.AddSheet("data")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if name := sheet.Name(); name != "data" || mso.CountSheets() != 2 {
+        t.Fatalf("sheet %q of %v sheets", name, mso.CountSheets())
+    }
+    if first, _ := mso.Sheet(1); first.Name() != "data" {
+        t.Fatalf("sheet added is not before the active one: %v", first.Name())
+    }
+    for i, row := range [][]interface{}{{"name", "qty"}, {"a", 1}, {"b", 2}} {
+        for j, val := range row {
+            if err = sheet.PutCell(i+1, j+1, val); err != nil {
+                t.Fatal(err)
+            }
+        }
+    }
+    if err = sheet.PutRange("A4:B4", []interface{}{"c", 3}); err != nil {
+        t.Fatal(err)
+    }
+    if val, _ := sheet.GetCell(2, 2); val != 1.0 {
+        t.Fatalf("B2 is %#v, want float64", val)
+    }
+    if val, _ := sheet.GetCell(9, 9); val != "" {
+        t.Fatalf("empty I9 is %#v", val)
+    }
+
+    rows := [][]interface{}{}
+    sheet.ReadRow(func(row []interface{}) (int) {
+        rows = append(rows, row)
+        return 0
+    })
+    if len(rows) != 4 || rows[0][0] != "name" || rows[3][0] != "c" || rows[3][1] != 3.0 {
+        t.Fatalf("%v", rows)
+    }
+    rows = rows[:0]
+    sheet.ReadRow("B", 2, func(row []interface{}) (int) {
+        rows = append(rows, row)
+        if len(rows) == 2 {
+            return -1
+        }
+        return 0
+    })
+    if len(rows) != 2 || len(rows[0]) != 1 || rows[0][0] != 1.0 || rows[1][0] != 2.0 {
+        t.Fatalf("rows from B2 until -1 are %v", rows)
+    }
+}
+
+func TestFakeScripted(t *testing.T) {
+    app := NewFakeExcel()
+    quits := 0
+    app.OnCall("Quit", func(args... interface{}) (interface{}, error) {
+        quits ++
+        return nil, nil
+    })
+    mso, err := New(Option{"Backend": app})
+    if err != nil {
+        t.Fatal(err)
+    }
+    wb, err := mso.ActiveWorkBook()
+    if err != nil || wb.Name() != "Book1" {
+        t.Fatalf("%v, %v", wb.Name(), err)
+    }
+    if err = mso.Quit(); err != nil || quits != 1 {
+        t.Fatalf("Quit is called %v times: %v", quits, err)
+    }
+    if calls := strings.Join(app.Calls, ";"); ! strings.Contains(calls, "Call Quit") {
+        t.Fatalf("calls of Application: %v", calls)
+    }
+    if _, err = New(Option{"Backend": "none"}); err == nil || ! strings.Contains(err.Error(), "unknown backend") {
+        t.Fatalf("unknown backend: %v", err)
+    }
+}
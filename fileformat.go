@@ -0,0 +1,264 @@
+package excel
+
+import (
+    "fmt"
+    "path/filepath"
+    "strings"
+)
+
+//FileFormat is XlFileFormat of Excel, the format of WorkBook.SaveAs.
+type FileFormat int
+
+//the values of XlFileFormat.
+const (
+    XlAddIn                       FileFormat = 18       //Microsoft Excel 97-2003 Add-In, xla
+    XlAddIn8                      FileFormat = 18
+    XlCSV                         FileFormat = 6        //CSV, csv
+    XlCSVMac                      FileFormat = 22
+    XlCSVMSDOS                    FileFormat = 24
+    XlCSVUTF8                     FileFormat = 62       //UTF-8 CSV, csv, Excel 2016 and later
+    XlCSVWindows                  FileFormat = 23
+    XlCurrentPlatformText         FileFormat = -4158    //text, txt
+    XlDBF2                        FileFormat = 7
+    XlDBF3                        FileFormat = 8
+    XlDBF4                        FileFormat = 11
+    XlDIF                         FileFormat = 9
+    XlExcel12                     FileFormat = 50       //Excel Binary Workbook, xlsb
+    XlExcel2                      FileFormat = 16
+    XlExcel2FarEast               FileFormat = 27
+    XlExcel3                      FileFormat = 29
+    XlExcel4                      FileFormat = 33
+    XlExcel4Workbook              FileFormat = 35
+    XlExcel5                      FileFormat = 39
+    XlExcel7                      FileFormat = 39
+    XlExcel8                      FileFormat = 56       //Excel 97-2003 Workbook, xls
+    XlExcel9795                   FileFormat = 43
+    XlHtml                        FileFormat = 44       //HTML, html
+    XlIntlAddIn                   FileFormat = 26
+    XlIntlMacro                   FileFormat = 25
+    XlOpenDocumentSpreadsheet     FileFormat = 60       //OpenDocument Spreadsheet, ods
+    XlOpenXMLAddIn                FileFormat = 55       //Excel Add-In, xlam
+    XlOpenXMLStrictWorkbook       FileFormat = 61       //Strict Open XML Spreadsheet, xlsx, Excel 2013 and later
+    XlOpenXMLTemplate             FileFormat = 54       //Excel Template, xltx
+    XlOpenXMLTemplateMacroEnabled FileFormat = 53       //Excel Macro-Enabled Template, xltm
+    XlOpenXMLWorkbook             FileFormat = 51       //Excel Workbook, xlsx
+    XlOpenXMLWorkbookMacroEnabled FileFormat = 52       //Excel Macro-Enabled Workbook, xlsm
+    XlSYLK                        FileFormat = 2
+    XlTemplate                    FileFormat = 17       //Excel 97-2003 Template, xlt
+    XlTemplate8                   FileFormat = 17
+    XlTextMac                     FileFormat = 19
+    XlTextMSDOS                   FileFormat = 21
+    XlTextPrinter                 FileFormat = 36       //Formatted Text (Space delimited), prn
+    XlTextWindows                 FileFormat = 20       //Text (Tab delimited), txt
+    XlUnicodeText                 FileFormat = 42       //Unicode Text, txt
+    XlWebArchive                  FileFormat = 45       //Single File Web Page, mht
+    XlWJ2WD1                      FileFormat = 14
+    XlWJ3                         FileFormat = 40
+    XlWJ3FJ3                      FileFormat = 41
+    XlWK1                         FileFormat = 5
+    XlWK1ALL                      FileFormat = 31
+    XlWK1FMT                      FileFormat = 30
+    XlWK3                         FileFormat = 15
+    XlWK3FM3                      FileFormat = 32
+    XlWK4                         FileFormat = 38
+    XlWKS                         FileFormat = 4
+    XlWorkbookDefault             FileFormat = 51       //the workbook of Excel 2007 and later, xlsx
+    XlWorkbookNormal              FileFormat = -4143    //the workbook of Excel 2003 and earlier, xls
+    XlWorks2FarEast               FileFormat = 28
+    XlWQ1                         FileFormat = 34
+    XlXMLSpreadsheet              FileFormat = 46       //XML Spreadsheet 2003, xml
+)
+
+//formatInfo is the extensions of a format, the first is appended to a file name without any of them,
+//and the versions of Excel saving it, 0 for no limit.
+type formatInfo struct {
+    exts  []string
+    since float64
+    until float64
+}
+
+var fileFormats = map[FileFormat]formatInfo {
+    XlAddIn:                       {[]string{"xla"}, 0, 0},
+    XlCSV:                         {[]string{"csv"}, 0, 0},
+    XlCSVMac:                      {[]string{"csv"}, 0, 0},
+    XlCSVMSDOS:                    {[]string{"csv"}, 0, 0},
+    XlCSVUTF8:                     {[]string{"csv"}, 16, 0},
+    XlCSVWindows:                  {[]string{"csv"}, 0, 0},
+    XlCurrentPlatformText:         {[]string{"txt"}, 0, 0},
+    XlDBF2:                        {[]string{"dbf"}, 0, 11},
+    XlDBF3:                        {[]string{"dbf"}, 0, 11},
+    XlDBF4:                        {[]string{"dbf"}, 0, 11},
+    XlDIF:                         {[]string{"dif"}, 0, 0},
+    XlExcel12:                     {[]string{"xlsb"}, 12, 0},
+    XlExcel2:                      {[]string{"xls"}, 0, 11},
+    XlExcel2FarEast:               {[]string{"xls"}, 0, 11},
+    XlExcel3:                      {[]string{"xls"}, 0, 11},
+    XlExcel4:                      {[]string{"xls"}, 0, 11},
+    XlExcel4Workbook:              {[]string{"xlw"}, 0, 11},
+    XlExcel5:                      {[]string{"xls"}, 0, 11},
+    XlExcel8:                      {[]string{"xls"}, 12, 0},
+    XlExcel9795:                   {[]string{"xls"}, 0, 11},
+    XlHtml:                        {[]string{"html", "htm"}, 0, 0},
+    XlIntlAddIn:                   {[]string{"xla"}, 0, 11},
+    XlIntlMacro:                   {[]string{"xls"}, 0, 11},
+    XlOpenDocumentSpreadsheet:     {[]string{"ods"}, 12, 0},
+    XlOpenXMLAddIn:                {[]string{"xlam"}, 12, 0},
+    XlOpenXMLStrictWorkbook:       {[]string{"xlsx"}, 15, 0},
+    XlOpenXMLTemplate:             {[]string{"xltx"}, 12, 0},
+    XlOpenXMLTemplateMacroEnabled: {[]string{"xltm"}, 12, 0},
+    XlOpenXMLWorkbook:             {[]string{"xlsx"}, 12, 0},
+    XlOpenXMLWorkbookMacroEnabled: {[]string{"xlsm"}, 12, 0},
+    XlSYLK:                        {[]string{"slk"}, 0, 0},
+    XlTemplate:                    {[]string{"xlt"}, 0, 0},
+    XlTextMac:                     {[]string{"txt"}, 0, 0},
+    XlTextMSDOS:                   {[]string{"txt"}, 0, 0},
+    XlTextPrinter:                 {[]string{"prn"}, 0, 0},
+    XlTextWindows:                 {[]string{"txt"}, 0, 0},
+    XlUnicodeText:                 {[]string{"txt"}, 0, 0},
+    XlWebArchive:                  {[]string{"mht", "mhtml"}, 0, 0},
+    XlWJ2WD1:                      {[]string{"wj2"}, 0, 11},
+    XlWJ3:                         {[]string{"wj3"}, 0, 11},
+    XlWJ3FJ3:                      {[]string{"wj3"}, 0, 11},
+    XlWK1:                         {[]string{"wk1"}, 0, 11},
+    XlWK1ALL:                      {[]string{"wk1"}, 0, 11},
+    XlWK1FMT:                      {[]string{"wk1"}, 0, 11},
+    XlWK3:                         {[]string{"wk3"}, 0, 11},
+    XlWK3FM3:                      {[]string{"wk3"}, 0, 11},
+    XlWK4:                         {[]string{"wk4"}, 0, 11},
+    XlWKS:                         {[]string{"wks"}, 0, 11},
+    XlWorkbookNormal:              {[]string{"xls"}, 0, 0},
+    XlWorks2FarEast:               {[]string{"wks"}, 0, 11},
+    XlWQ1:                         {[]string{"wq1"}, 0, 11},
+    XlXMLSpreadsheet:              {[]string{"xml"}, 0, 0},
+}
+
+//the format inferred from an extension.
+var extFormats = map[string]FileFormat {
+    "xlsx": XlOpenXMLWorkbook, "xlsm": XlOpenXMLWorkbookMacroEnabled, "xlsb": XlExcel12, "xls": XlExcel8,
+    "xltx": XlOpenXMLTemplate, "xltm": XlOpenXMLTemplateMacroEnabled, "xlt": XlTemplate,
+    "xlam": XlOpenXMLAddIn, "xla": XlAddIn, "ods": XlOpenDocumentSpreadsheet, "xml": XlXMLSpreadsheet,
+    "csv": XlCSV, "txt": XlCurrentPlatformText, "prn": XlTextPrinter, "dif": XlDIF, "slk": XlSYLK,
+    "html": XlHtml, "htm": XlHtml, "mht": XlWebArchive, "mhtml": XlWebArchive,
+}
+
+//the default of MSO.FILEFORMAT, names of formats for SaveAs.
+func fileFormatNames() (map[string]int) {
+    names := map[string]int {"csvutf8":int(XlCSVUTF8), "unicode":int(XlUnicodeText)}
+    for ext, ff := range extFormats {
+        names[ext] = int(ff)
+    }
+    return names
+}
+
+//the workbook format of the version, xls before Excel 2007.
+func defaultFormat(version float64) (FileFormat) {
+    if version > 0 && version < 12 {
+        return XlWorkbookNormal
+    }
+    return XlWorkbookDefault
+}
+
+//the lowercase extension without the dot.
+func fileExt(full string) (string) {
+    return strings.ToLower(strings.TrimPrefix(filepath.Ext(full), "."))
+}
+
+//FormatOf infers the format of SaveAs from the extension of full by the version of Excel, 0 for the latest.
+//a name without extension is the default workbook of the version.
+func FormatOf(full string, version float64) (ff FileFormat, err error) {
+    ext := fileExt(full)
+    switch ext {
+        case "":
+            return defaultFormat(version), nil
+        case "pdf":
+            return 0, fmt.Errorf("%v is not saved by SaveAs, but by ExportPDF", full)
+        case "xps":
+            return 0, fmt.Errorf("%v is not saved by SaveAs, but by ExportXPS", full)
+    }
+    ff, ok := extFormats[ext]
+    if ! ok {
+        return 0, fmt.Errorf("unknown format of %v, the file format is needed", full)
+    }
+    if ff == XlExcel8 && version > 0 && version < 12 {
+        ff = XlWorkbookNormal
+    }
+    return ff, checkFormat(ff, full, version)
+}
+
+//an error if the version can not save the format, or full has the extension of another format.
+func checkFormat(ff FileFormat, full string, version float64) (error) {
+    info, ok := fileFormats[ff]
+    if ! ok {
+        return nil
+    }
+    if version > 0 && (version < info.since || info.until > 0 && version > info.until) {
+        return fmt.Errorf("file format %v of %v is not supported by Excel %v", ff, full, version)
+    }
+    ext := fileExt(full)
+    if _, known := extFormats[ext]; known && ! hasExt(info, ext) {
+        return fmt.Errorf("file format %v does not match the extension of %v", ff, full)
+    }
+    return nil
+}
+
+//
+func hasExt(info formatInfo, ext string) (bool) {
+    for _, one := range info.exts {
+        if one == ext {
+            return true
+        }
+    }
+    return false
+}
+
+//the arguments of SaveAs with the file name and format, args[1] is a name in names, a number or nil to infer it.
+//args of the caller are not changed.
+//a file name without the extension of the format gets it, the name of a format is appended as before.
+func saveAsArgs(args []interface{}, names map[string]int, version float64) ([]interface{}, error) {
+    full, ok := args[0].(string)
+    if ! ok {
+        return args, nil
+    }
+    var format interface{}
+    if len(args) > 1 {
+        format = args[1]
+    }
+    var ff FileFormat
+    var err error
+    switch one := format.(type) {
+        case nil:
+            if ff, err = FormatOf(full, version); err != nil {
+                return nil, err
+            }
+        case string:
+            name := strings.ToLower(one)
+            n, ok := names[name]
+            if ! ok {
+                return nil, fmt.Errorf("unknown file format %q of %v", one, full)
+            }
+            ff = FileFormat(n)
+            if info, known := fileFormats[ff]; known {
+                if ! hasExt(info, fileExt(full)) {
+                    full += "."+info.exts[0]
+                }
+            } else if ! strings.HasSuffix(strings.ToLower(full), "."+name) {
+                full += "."+name
+            }
+        default:
+            n, ok := toInt(one)
+            if ! ok {
+                return nil, fmt.Errorf("incorrect file format %+v of %v", format, full)
+            }
+            ff = FileFormat(n)
+    }
+    if info, known := fileFormats[ff]; known && fileExt(full) == "" {
+        full += "."+info.exts[0]
+    }
+    if err = checkFormat(ff, full, version); err != nil {
+        return nil, err
+    }
+    ret := make([]interface{}, max(len(args), 2))
+    copy(ret, args)
+    ret[0], ret[1] = full, int(ff)
+    return ret, nil
+}
@@ -0,0 +1,69 @@
+package excel
+
+import (
+    "testing"
+)
+
+func TestFormatOf(t *testing.T) {
+    tests := []struct {
+        full    string
+        version float64
+        want    FileFormat
+    }{
+        {"a.xlsx", 16, XlOpenXMLWorkbook},
+        {"a.xlsm", 16, XlOpenXMLWorkbookMacroEnabled},
+        {"a.xlsb", 16, XlExcel12},
+        {"a.ods", 16, XlOpenDocumentSpreadsheet},
+        {"a.xls", 11, XlWorkbookNormal},
+        {"a.xls", 16, XlExcel8},
+        {"a", 11, XlWorkbookNormal},
+        {"a", 16, XlWorkbookDefault},
+        {"a.xlsx", 11, 0},
+        {"a.pdf", 16, 0},
+        {"a.dat", 16, 0},
+    }
+    for _, test := range tests {
+        ff, err := FormatOf(test.full, test.version)
+        if (err == nil) != (test.want != 0) || err == nil && ff != test.want {
+            t.Errorf("FormatOf(%q, %v) = %v, %v, want %v", test.full, test.version, ff, err, test.want)
+        }
+    }
+}
+
+func TestSaveAsArgs(t *testing.T) {
+    names := fileFormatNames()
+    args, err := saveAsArgs([]interface{}{"a"}, names, 11)
+    if err != nil || len(args) != 2 || args[0] != "a.xls" || args[1] != int(XlWorkbookNormal) {
+        t.Fatal(args, err)
+    }
+    args, err = saveAsArgs([]interface{}{"a", "csvutf8"}, names, 16)
+    if err != nil || args[0] != "a.csv" || args[1] != int(XlCSVUTF8) {
+        t.Fatal(args, err)
+    }
+    if args, _ = saveAsArgs([]interface{}{"a.dat", 6}, names, 16); args[0] != "a.dat" {
+        t.Fatal(args)
+    }
+    for _, args := range [][]interface{}{{"a.csv", XlCSVUTF8}, {"a.xlsx", XlCSV}, {"a", "nope"}} {
+        version := 16.0
+        if args[1] == XlCSVUTF8 {
+            version = 15
+        }
+        if _, err = saveAsArgs(args, names, version); err == nil {
+            t.Errorf("saveAsArgs(%v) of version %v is accepted", args, version)
+        }
+    }
+}
+
+func TestSaveAsArgsCopy(t *testing.T) {
+    mine := []interface{}{"report", "csv", missingArg{}, 3}
+    args, err := saveAsArgs(mine[:2], fileFormatNames(), 16)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if mine[0] != "report" || mine[1] != "csv" || mine[2] != (missingArg{}) {
+        t.Fatalf("args of the caller are changed to %v", mine)
+    }
+    if len(args) != 2 || args[0] != "report.csv" || args[1] != int(XlCSV) {
+        t.Fatal(args)
+    }
+}
@@ -0,0 +1,139 @@
+package excel
+
+import (
+    "fmt"
+)
+
+//FormulaKind is the property of a formula, by its reference style, language and array behavior.
+type FormulaKind string
+
+const (
+    FormulaA1        FormulaKind = "Formula"            //A1 style in English
+    FormulaR1C1      FormulaKind = "FormulaR1C1"        //R1C1 style in English
+    FormulaLocal     FormulaKind = "FormulaLocal"       //A1 style in the language of the user
+    FormulaR1C1Local FormulaKind = "FormulaR1C1Local"   //R1C1 style in the language of the user
+    FormulaArray     FormulaKind = "FormulaArray"       //an array formula (CSE) over the whole range, A1 or R1C1 style
+    Formula2         FormulaKind = "Formula2"           //a dynamic array formula which spills, Excel 365 or later
+    Formula2R1C1     FormulaKind = "Formula2R1C1"       //a dynamic array formula in R1C1 style
+)
+
+//
+func formulaKind(kind []FormulaKind) (FormulaKind, error) {
+    if len(kind) == 0 {
+        return FormulaA1, nil
+    }
+    switch kind[0] {
+        case FormulaA1, FormulaR1C1, FormulaLocal, FormulaR1C1Local, FormulaArray, Formula2, Formula2R1C1:
+            return kind[0], nil
+    }
+    return "", fmt.Errorf("unknown formula kind %q", kind[0])
+}
+
+//Formula gets the formula of the cell, Formula by default, a constant reads as its text and an empty cell as "".
+func (cell Cell) Formula(kind... FormulaKind) (formula string, err error) {
+    defer Except("Cell.Formula", &err)
+    name, err := formulaKind(kind)
+    if err != nil {
+        return
+    }
+    return formulaString(mustGet(cell.Dispatcher, string(name))), nil
+}
+
+//PutFormula puts a formula like "=SUM(A1:A9)" into the cell, Formula by default, a text without "=" is a constant.
+func (cell Cell) PutFormula(formula string, kind... FormulaKind) (err error) {
+    defer Except("Cell.PutFormula", &err)
+    name, err := formulaKind(kind)
+    if err != nil {
+        return
+    }
+    mustPut(cell.Dispatcher, string(name), formula)
+    return
+}
+
+//HasFormula reports if the cell has a formula.
+func (cell Cell) HasFormula() (has bool, err error) {
+    defer Except("Cell.HasFormula", &err)
+    has, _ = mustGet(cell.Dispatcher, "HasFormula").(bool)
+    return
+}
+
+//Formula gets the formulas of the range as rows, Formula by default.
+//a formula got for the whole range like FormulaArray is in every cell, a constant reads as its text and an empty cell as "".
+func (rg Range) Formula(kind... FormulaKind) (formulas [][]string, err error) {
+    defer Except("Range.Formula", &err)
+    name, err := formulaKind(kind)
+    if err != nil {
+        return
+    }
+    rows, columns := rg.size()
+    val := mustGet(rg.Dispatcher, string(name))
+    all, whole := val.([][]interface{})
+    formulas = make([][]string, rows)
+    for i := range formulas {
+        formulas[i] = make([]string, columns)
+        for j := range formulas[i] {
+            if ! whole {
+                formulas[i][j] = formulaString(val)
+            } else if i < len(all) && j < len(all[i]) {
+                formulas[i][j] = formulaString(all[i][j])
+            }
+        }
+    }
+    return
+}
+
+//PutFormula puts formulas into the range, Formula by default.
+//a string is put into every cell, Excel adjusts its relative references for each cell,
+//or as one array formula over the range by FormulaArray;
+//a 2-D slice like [][]string or [][]interface{} is put cell by cell in one call, its rows and columns must be of the range.
+func (rg Range) PutFormula(formula interface{}, kind... FormulaKind) (err error) {
+    defer Except("Range.PutFormula", &err)
+    name, err := formulaKind(kind)
+    if err != nil {
+        return
+    }
+    if _, ok := formula.(string); ok {
+        mustPut(rg.Dispatcher, string(name), formula)
+        return
+    }
+    if name == FormulaArray {
+        return fmt.Errorf("FormulaArray is one formula over the range, got %T", formula)
+    }
+    values, columns, err := arrayRows(formula)
+    if err != nil {
+        return
+    }
+    if rows, cols := rg.size(); len(values) != rows || columns != cols {
+        return fmt.Errorf("%v rows and %v columns of formulas are put into a range of %v rows and %v columns", len(values), columns, rows, cols)
+    }
+    mustPut(rg.Dispatcher, string(name), values)
+    return
+}
+
+//HasFormula reports if all cells of the range have formulas, and if any has,
+//as HasFormula of Excel is null, which is read as "", if some have.
+func (rg Range) HasFormula() (all bool, any bool, err error) {
+    defer Except("Range.HasFormula", &err)
+    switch mustGet(rg.Dispatcher, "HasFormula") {
+        case true:
+            return true, true, nil
+        case false:
+            return false, false, nil
+    }
+    return false, true, nil
+}
+
+//Null of Excel, like FormulaArray of a part of an array, is "".
+func formulaString(val interface{}) (string) {
+    if val == nil {
+        return ""
+    }
+    return String(val)
+}
+
+//the rows and columns of the range.
+func (rg Range) size() (rows int, columns int) {
+    rows, _ = toInt(MustGetProperty(rg.Dispatcher, "Rows", "Count"))
+    columns, _ = toInt(MustGetProperty(rg.Dispatcher, "Columns", "Count"))
+    return
+}
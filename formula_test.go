@@ -0,0 +1,105 @@
+package excel
+
+import (
+    "reflect"
+    "strings"
+    "testing"
+)
+
+//a fake sheet with numbers in A1:B2.
+func formulaSheet(t *testing.T) (*MSO, Sheet) {
+    mso, err := New(Option{"Backend": "fake"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    sheet, _ := mso.Sheet(1)
+    sheet.PutRange("A1:B2", [][]interface{}{{1, 2}, {3, 4}})
+    return mso, sheet
+}
+
+func TestFormulaKindUnknown(t *testing.T) {
+    mso, sheet := formulaSheet(t)
+    defer mso.Quit()
+    nope := FormulaKind("Nope")
+    cell, rg := sheet.Cell(1, 3), sheet.Range("C1:C2")
+    _, err1 := cell.Formula(nope)
+    err2 := cell.PutFormula("=A1", nope)
+    _, err3 := rg.Formula(nope)
+    err4 := rg.PutFormula("=A1", nope)
+    for i, err := range []error{err1, err2, err3, err4} {
+        if err == nil || ! strings.Contains(err.Error(), `unknown formula kind "Nope"`) {
+            t.Errorf("%v: %v", i+1, err)
+        }
+    }
+    if all, any, _ := rg.HasFormula(); all || any {
+        t.Fatal("a formula of an unknown kind is put")
+    }
+}
+
+func TestRangePutFormula(t *testing.T) {
+    mso, sheet := formulaSheet(t)
+    defer mso.Quit()
+    rg := sheet.Range("D1:E2")
+    for _, formula := range []interface{}{[][]string{{"=A1"}, {"=A2"}}, [][]string{{"=A1", "=B1"}}, [][]interface{}{{"=A1", "=B1", "=C1"}, {"=A2", "=B2", "=C2"}}, 1} {
+        if err := rg.PutFormula(formula); err == nil {
+            t.Fatalf("%v is put into D1:E2", formula)
+        }
+    }
+    if err := rg.PutFormula([][]string{{"=A1", "=B1"}, {"=A2", "=B2"}}, FormulaArray); err == nil || ! strings.Contains(err.Error(), "FormulaArray") {
+        t.Fatalf("formulas by FormulaArray: %v", err)
+    }
+    if all, any, _ := rg.HasFormula(); all || any {
+        t.Fatal("formulas are put by an incorrect call")
+    }
+    if err := rg.PutFormula([][]interface{}{{"=A1*2", "=B1*2"}, {"=A2*2", 5}}); err != nil {
+        t.Fatal(err)
+    }
+    if all, any, err := rg.HasFormula(); all || ! any || err != nil {
+        t.Fatalf("HasFormula of a mix is %v, %v, %v", all, any, err)
+    }
+    if err := sheet.Cell(2, 5).PutFormula("=B2*2"); err != nil {
+        t.Fatal(err)
+    }
+    if all, any, err := rg.HasFormula(); ! all || ! any || err != nil {
+        t.Fatalf("HasFormula of all is %v, %v, %v", all, any, err)
+    }
+    if val, _ := sheet.GetCell(2, 5); val != "" {
+        t.Fatalf("E2 reads %v", val)
+    }
+}
+
+func TestRangeFormula(t *testing.T) {
+    mso, sheet := formulaSheet(t)
+    defer mso.Quit()
+    if err := sheet.Range("C1:C2").PutFormula("=A1+B1"); err != nil {
+        t.Fatal(err)
+    }
+    if err := sheet.Range("D1:D2").PutFormula("=A1:A2*2", FormulaArray); err != nil {
+        t.Fatal(err)
+    }
+    tests := []struct {
+        rang string
+        kind FormulaKind
+        want [][]string
+    }{
+        {"C1:C2", FormulaA1, [][]string{{"=A1+B1"}, {"=A2+B2"}}},
+        {"C1:C2", FormulaR1C1, [][]string{{"=RC[-2]+RC[-1]"}, {"=RC[-2]+RC[-1]"}}},
+        {"B1:C2", FormulaA1, [][]string{{"2", "=A1+B1"}, {"4", "=A2+B2"}}},
+        {"C2", FormulaA1, [][]string{{"=A2+B2"}}},
+        {"D1:D2", FormulaArray, [][]string{{"=A1:A2*2"}, {"=A1:A2*2"}}},
+        {"D1:E2", FormulaArray, [][]string{{"", ""}, {"", ""}}},
+        {"E1:E2", FormulaA1, [][]string{{""}, {""}}},
+    }
+    for _, test := range tests {
+        got, err := sheet.Range(test.rang).Formula(test.kind)
+        if err != nil || ! reflect.DeepEqual(got, test.want) {
+            t.Errorf("%v of %v is %q, %v, want %q", test.kind, test.rang, got, err, test.want)
+        }
+    }
+    if f, err := sheet.Cell(2, 3).Formula(FormulaR1C1); f != "=RC[-2]+RC[-1]" || err != nil {
+        t.Fatalf("FormulaR1C1 of C2 is %q, %v", f, err)
+    }
+    if has, err := sheet.Cell(1, 1).HasFormula(); has || err != nil {
+        t.Fatalf("HasFormula of A1 is %v, %v", has, err)
+    }
+}
@@ -0,0 +1,1019 @@
+package excel
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+    "unicode"
+    "unicode/utf8"
+)
+
+//ErrFormula is wrapped by the errors of parsing formulas.
+var ErrFormula = errors.New("incorrect formula")
+
+//FormulaTokenKind is the kind of a token of a formula.
+type FormulaTokenKind int
+
+const (
+    TokenNumber FormulaTokenKind = iota
+    TokenString
+    TokenBool
+    TokenError          //like #N/A or #DIV/0!
+    TokenRef            //a reference to cells, a defined name or a structured reference of a table
+    TokenFunc           //the name of a function, followed by TokenOpen
+    TokenOperator       //+ - * / ^ & = <> < > <= >= % : @ #, and " " of intersection
+    TokenOpen
+    TokenClose
+    TokenSep            //, of arguments, array columns or union
+    TokenRowSep         //; of array rows
+    TokenArrayOpen
+    TokenArrayClose
+    tokenSpace
+)
+
+//FormulaToken is a token of a formula, Pos is its byte offset in the formula.
+type FormulaToken struct {
+    Kind  FormulaTokenKind
+    Text  string
+    Pos   int
+    Ref   *FormulaRef   //of TokenRef
+    Space string        //the whitespace before it
+}
+
+//FormulaRef is a reference of a formula: cells, a defined name or a structured reference of a table,
+//the positions of cells are absolute even for relative references, A1 or R1C1 is only the notation.
+type FormulaRef struct {
+    Path    string      //the directory of an external workbook like `C:\Data\`
+    Book    string      //an external workbook like "Sales.xlsx", or its index like "1" of [1]Sheet1!A1
+    Sheet   string      //"" for the sheet of the formula
+    Sheet2  string      //the last sheet of a 3-D reference like Sheet1:Sheet3!A1
+    Range   RangeRef    //the cells, Range.Sheet is not used
+    Name    string      //a defined name like Total of Sheet1!Total
+    Table   string      //a table like Sales of Sales[[#Totals],[Amount]], "" in the table itself like [@Amount]
+    Spec    string      //the brackets of a structured reference like [[#Totals],[Amount]]
+    Invalid bool        //#REF!, like a reference to deleted cells
+}
+
+//FormulaNodeKind is the kind of a node of the syntax tree of a formula.
+type FormulaNodeKind int
+
+const (
+    NodeNumber FormulaNodeKind = iota
+    NodeString
+    NodeBool
+    NodeError
+    NodeRef
+    NodeFunc            //Text is the name, Args are the arguments
+    NodeUnary           //- + or @ before Args[0]
+    NodeBinary          //Args[0] Text Args[1], Text is like "+", ":", "," of union or " " of intersection
+    NodePostfix         //% or # after Args[0]
+    NodeParen
+    NodeArray           //Rows of constants like {1,2;3,4}
+    NodeMissing         //an omitted argument like the second of IF(A1,,1)
+)
+
+//FormulaNode is a node of the syntax tree of a formula.
+type FormulaNode struct {
+    Kind   FormulaNodeKind
+    Text   string           //the number as written, the value of a string, TRUE or FALSE, the error, the operator or the function
+    Ref    *FormulaRef
+    Args   []*FormulaNode
+    Rows   [][]*FormulaNode
+    Spaces []string         //the whitespace before each token of the node itself, like the name, "(", "," and ")" of a function
+}
+
+//Formula is a parsed formula, references without a sheet are of Sheet, "" if it is unknown.
+type Formula struct {
+    Sheet string
+    Root  *FormulaNode
+    Space string            //the whitespace at the end
+}
+
+//errors of Excel.
+var formulaErrors = []string{"#NULL!", "#DIV/0!", "#VALUE!", "#REF!", "#NAME?", "#NUM!", "#N/A", "#GETTING_DATA",
+    "#SPILL!", "#CALC!", "#FIELD!", "#BLOCKED!", "#CONNECT!", "#BUSY!", "#UNKNOWN!", "#PYTHON!"}
+
+//
+func formulaError(formula string, pos int, reason string) (error) {
+    return fmt.Errorf("%w %q: %v at %v", ErrFormula, formula, reason, pos)
+}
+
+//TokenizeFormula splits a formula in A1 notation like "=SUM(A1:B2)*2" into tokens, "=" at the beginning is optional.
+func TokenizeFormula(formula string) ([]FormulaToken, error) {
+    return tokenizeFormula(formula, false, CellRef{})
+}
+
+//TokenizeFormulaR1C1 splits a formula in R1C1 notation into tokens, relative references are of the cell base.
+func TokenizeFormulaR1C1(formula string, base CellRef) ([]FormulaToken, error) {
+    return tokenizeFormula(formula, true, base)
+}
+
+//ParseFormula parses a formula in A1 notation like "=SUM(A1:B2)*2", "=" at the beginning is optional.
+func ParseFormula(formula string) (*Formula, error) {
+    tokens, err := TokenizeFormula(formula)
+    if err != nil {
+        return nil, err
+    }
+    return parseFormula(formula, tokens)
+}
+
+//ParseFormulaR1C1 parses a formula in R1C1 notation like "=SUM(R1C1:R[1]C)", relative references are of the cell base.
+func ParseFormulaR1C1(formula string, base CellRef) (*Formula, error) {
+    tokens, err := TokenizeFormulaR1C1(formula, base)
+    if err != nil {
+        return nil, err
+    }
+    return parseFormula(formula, tokens)
+}
+
+//formulaLexer splits a formula into tokens.
+type formulaLexer struct {
+    s      string
+    i      int
+    r1c1   bool
+    base   CellRef
+    tokens []FormulaToken
+}
+
+//
+func tokenizeFormula(formula string, r1c1 bool, base CellRef) ([]FormulaToken, error) {
+    lx := &formulaLexer{s: formula, r1c1: r1c1, base: base}
+    if strings.HasPrefix(formula, "=") {
+        lx.i = 1
+    }
+    for lx.i < len(lx.s) {
+        if err := lx.next(); err != nil {
+            return nil, err
+        }
+    }
+    return lx.spaces(), nil
+}
+
+//
+func (lx *formulaLexer) add(kind FormulaTokenKind, start int, ref *FormulaRef) {
+    lx.tokens = append(lx.tokens, FormulaToken{Kind: kind, Text: lx.s[start:lx.i], Pos: start, Ref: ref})
+}
+
+//
+func (lx *formulaLexer) err(reason string) (error) {
+    return formulaError(lx.s, lx.i, reason)
+}
+
+//the last token ends an operand.
+func (lx *formulaLexer) afterOperand() (bool) {
+    if len(lx.tokens) == 0 {
+        return false
+    }
+    switch last := lx.tokens[len(lx.tokens)-1]; last.Kind {
+        case TokenRef, TokenClose, TokenNumber, TokenString, TokenBool, TokenError, TokenArrayClose:
+            return true
+        case TokenOperator:
+            return last.Text == "%" || last.Text == "#"
+    }
+    return false
+}
+
+//
+func (lx *formulaLexer) next() (error) {
+    s, start := lx.s, lx.i
+    ch := s[lx.i]
+    switch {
+        case ch == ' ' || ch == '\n' || ch == '\r' || ch == '\t':
+            for lx.i < len(s) && strings.IndexByte(" \n\r\t", s[lx.i]) != -1 {
+                lx.i ++
+            }
+            lx.add(tokenSpace, start, nil)
+        case ch == '"':
+            for lx.i ++; ; lx.i ++ {
+                if lx.i >= len(s) {
+                    return formulaError(s, start, "unclosed string")
+                }
+                if s[lx.i] == '"' {
+                    if lx.i+1 < len(s) && s[lx.i+1] == '"' {
+                        lx.i ++
+                        continue
+                    }
+                    break
+                }
+            }
+            lx.i ++
+            lx.add(TokenString, start, nil)
+        case ch == '#' && lx.afterOperand():
+            lx.i ++
+            lx.add(TokenOperator, start, nil)
+        case ch == '#':
+            for _, name := range formulaErrors {
+                if len(s) - lx.i >= len(name) && strings.EqualFold(s[lx.i:lx.i+len(name)], name) {
+                    lx.i += len(name)
+                    lx.add(TokenError, start, nil)
+                    return nil
+                }
+            }
+            return lx.err("unknown error")
+        case ch == '(':
+            lx.i ++
+            lx.add(TokenOpen, start, nil)
+        case ch == ')':
+            lx.i ++
+            lx.add(TokenClose, start, nil)
+        case ch == '{':
+            lx.i ++
+            lx.add(TokenArrayOpen, start, nil)
+        case ch == '}':
+            lx.i ++
+            lx.add(TokenArrayClose, start, nil)
+        case ch == ',':
+            lx.i ++
+            lx.add(TokenSep, start, nil)
+        case ch == ';':
+            lx.i ++
+            lx.add(TokenRowSep, start, nil)
+        case ch == '<' || ch == '>':
+            lx.i ++
+            if lx.i < len(s) && (s[lx.i] == '=' || ch == '<' && s[lx.i] == '>') {
+                lx.i ++
+            }
+            lx.add(TokenOperator, start, nil)
+        case strings.IndexByte("+-*/^&=%:@", ch) != -1:
+            lx.i ++
+            lx.add(TokenOperator, start, nil)
+        case ch == '\'':
+            return lx.quoted()
+        case ch == '[':
+            return lx.bracket()
+        default:
+            if ok, err := lx.cells(&FormulaRef{}, start); ok || err != nil {
+                return err
+            }
+            if ch >= '0' && ch <= '9' || ch == '.' {
+                return lx.number()
+            }
+            return lx.word()
+    }
+    return nil
+}
+
+//a number like 1, 1.5, .5 or 1.5E+3.
+func (lx *formulaLexer) number() (error) {
+    s, start := lx.s, lx.i
+    digits := func() {
+        for lx.i < len(s) && s[lx.i] >= '0' && s[lx.i] <= '9' {
+            lx.i ++
+        }
+    }
+    digits()
+    if lx.i < len(s) && s[lx.i] == '.' {
+        lx.i ++
+        digits()
+    }
+    if lx.i == start + 1 && s[start] == '.' {
+        return lx.err("unexpected .")
+    }
+    if lx.i < len(s) && s[lx.i] | 0x20 == 'e' {
+        j := lx.i + 1
+        if j < len(s) && (s[j] == '+' || s[j] == '-') {
+            j ++
+        }
+        if j < len(s) && s[j] >= '0' && s[j] <= '9' {
+            lx.i = j
+            digits()
+        }
+    }
+    if lx.i < len(s) && isWordByte(s, lx.i) {
+        return lx.err("unexpected "+s[lx.i:lx.i+1]+" after number")
+    }
+    lx.add(TokenNumber, start, nil)
+    return nil
+}
+
+//a letter, digit, or _ . \ ? of names.
+func isWordByte(s string, i int) (bool) {
+    r, _ := utf8.DecodeRuneInString(s[i:])
+    return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_.\\?", r)
+}
+
+//
+func (lx *formulaLexer) wordEnd(i int) (int) {
+    for i < len(lx.s) && isWordByte(lx.s, i) {
+        _, n := utf8.DecodeRuneInString(lx.s[i:])
+        i += n
+    }
+    return i
+}
+
+//a function, a sheet of a reference, a table, a defined name, TRUE or FALSE.
+func (lx *formulaLexer) word() (error) {
+    s, start := lx.s, lx.i
+    end := lx.wordEnd(lx.i)
+    if end == start {
+        return lx.err("unexpected "+s[lx.i:lx.i+1])
+    }
+    word := s[start:end]
+    lx.i = end
+    switch {
+        case end < len(s) && s[end] == '(':
+            lx.add(TokenFunc, start, nil)
+        case end < len(s) && s[end] == '!':
+            return lx.refPart(&FormulaRef{Sheet: word}, start, end+1)
+        case end < len(s) && s[end] == ':' && lx.sheet3D(end+1) != -1:
+            j := lx.sheet3D(end+1)
+            return lx.refPart(&FormulaRef{Sheet: word, Sheet2: s[end+1:j]}, start, j+1)
+        case end < len(s) && s[end] == '[':
+            return lx.table(&FormulaRef{Table: word}, start)
+        case strings.EqualFold(word, "TRUE") || strings.EqualFold(word, "FALSE"):
+            lx.add(TokenBool, start, nil)
+        default:
+            lx.add(TokenRef, start, &FormulaRef{Name: word})
+    }
+    return nil
+}
+
+//the end of the last sheet of Sheet1:Sheet3!, -1 if it is not.
+func (lx *formulaLexer) sheet3D(i int) (int) {
+    end := lx.wordEnd(i)
+    if end > i && end < len(lx.s) && lx.s[end] == '!' {
+        return end
+    }
+    return -1
+}
+
+//'My Sheet'!A1, 'C:\Data\[Book 1.xlsx]Sheet1'!A1 or 'Sheet 1:Sheet 3'!A1.
+func (lx *formulaLexer) quoted() (error) {
+    s, start := lx.s, lx.i
+    j := lx.i + 1
+    for ; ; j ++ {
+        if j >= len(s) {
+            return formulaError(s, start, "unclosed quote")
+        }
+        if s[j] == '\'' {
+            if j+1 < len(s) && s[j+1] == '\'' {
+                j ++
+                continue
+            }
+            break
+        }
+    }
+    if j+1 >= len(s) || s[j+1] != '!' {
+        return formulaError(s, start, "quoted sheet without !")
+    }
+    name := strings.Replace(s[start+1:j], "''", "'", -1)
+    ref := &FormulaRef{}
+    if k := strings.LastIndex(name, "]"); k != -1 {
+        b := strings.Index(name, "[")
+        if b == -1 || b > k {
+            return formulaError(s, start, "incorrect workbook")
+        }
+        ref.Path, ref.Book, name = name[:b], name[b+1:k], name[k+1:]
+    }
+    if k := strings.Index(name, ":"); k != -1 {
+        name, ref.Sheet2 = name[:k], name[k+1:]
+    }
+    ref.Sheet = name
+    return lx.refPart(ref, start, j+2)
+}
+
+//[Book1.xlsx]Sheet1!A1, [1]Sheet1!A1, [1]!Name, or a structured reference in a table like [@Amount].
+func (lx *formulaLexer) bracket() (error) {
+    s, start := lx.s, lx.i
+    if k := strings.IndexByte(s[start:], ']'); k != -1 && ! strings.ContainsAny(s[start+1:start+k], "[@#,'") {
+        book := s[start+1:start+k]
+        i := start + k + 1
+        if i < len(s) && s[i] == '!' {
+            return lx.refPart(&FormulaRef{Book: book}, start, i+1)
+        }
+        if end := lx.wordEnd(i); end > i && end < len(s) && s[end] == '!' {
+            return lx.refPart(&FormulaRef{Book: book, Sheet: s[i:end]}, start, end+1)
+        }
+    }
+    return lx.table(&FormulaRef{}, start)
+}
+
+//the brackets of a structured reference from lx.i, ' escapes a bracket.
+func (lx *formulaLexer) table(ref *FormulaRef, start int) (error) {
+    s, from := lx.s, lx.i
+    depth := 0
+    for ; lx.i < len(s); lx.i ++ {
+        switch s[lx.i] {
+            case '\'':
+                lx.i ++
+            case '[':
+                depth ++
+            case ']':
+                if depth --; depth == 0 {
+                    lx.i ++
+                    ref.Spec = s[from:lx.i]
+                    lx.add(TokenRef, start, ref)
+                    return nil
+                }
+        }
+    }
+    return formulaError(s, start, "unclosed [")
+}
+
+//the part after ! of a reference: cells, #REF!, a table or a defined name.
+func (lx *formulaLexer) refPart(ref *FormulaRef, start int, i int) (error) {
+    s := lx.s
+    lx.i = i
+    if len(s) - i >= 5 && strings.EqualFold(s[i:i+5], "#REF!") {
+        lx.i += 5
+        ref.Invalid = true
+        lx.add(TokenRef, start, ref)
+        return nil
+    }
+    if ok, err := lx.cells(ref, start); ok || err != nil {
+        return err
+    }
+    end := lx.wordEnd(i)
+    if end == i {
+        return lx.err("want a reference after !")
+    }
+    lx.i = end
+    if end < len(s) && s[end] == '[' {
+        ref.Table = s[i:end]
+        return lx.table(ref, start)
+    }
+    ref.Name = s[i:end]
+    lx.add(TokenRef, start, ref)
+    return nil
+}
+
+//cells from lx.i like A1, $A$1:B2, A:C or 3:5, or R1C1, R[-1]C:RC[2] in R1C1 notation,
+//an R1C1 reference out of the sheet is an error, letters beyond XFD are a name in A1 notation.
+func (lx *formulaLexer) cells(ref *FormulaRef, start int) (bool, error) {
+    part := lx.a1Part
+    if lx.r1c1 {
+        part = lx.r1c1Part
+    }
+    end := part(lx.i)
+    if end == -1 {
+        return false, nil
+    }
+    try := func(end int) (bool, error) {
+        if end < len(lx.s) && (isWordByte(lx.s, end) || strings.IndexByte("([!$", lx.s[end]) != -1) {
+            return false, nil
+        }
+        var rg RangeRef
+        var err error
+        if lx.r1c1 {
+            if rg, err = ParseR1C1(lx.s[lx.i:end], lx.base); err != nil {
+                return false, formulaError(lx.s, lx.i, err.Error())
+            }
+        } else if rg, err = ParseRangeRef(lx.s[lx.i:end]); err != nil {
+            return false, nil
+        }
+        ref.Range = rg
+        lx.i = end
+        lx.add(TokenRef, start, ref)
+        return true, nil
+    }
+    if end < len(lx.s) && lx.s[end] == ':' {
+        if end2 := part(end+1); end2 != -1 {
+            if ok, err := try(end2); ok || err != nil {
+                return ok, err
+            }
+        }
+    }
+    return try(end)
+}
+
+//the end of [$]letters[$]digits, [$]letters or [$]digits, -1 if it is not.
+func (lx *formulaLexer) a1Part(i int) (int) {
+    s := lx.s
+    j := i
+    if j < len(s) && s[j] == '$' {
+        j ++
+    }
+    k := j
+    for k < len(s) && (s[k] | 0x20) >= 'a' && (s[k] | 0x20) <= 'z' {
+        k ++
+    }
+    if k - j > 3 {
+        return -1
+    }
+    if k < len(s) && s[k] == '$' {
+        k ++
+    }
+    d := k
+    for d < len(s) && s[d] >= '0' && s[d] <= '9' {
+        d ++
+    }
+    if d == i || d == k && k == j {
+        return -1
+    }
+    return d
+}
+
+//the end of R[n]C[n], Rn, C[n] and so on, -1 if it is not.
+func (lx *formulaLexer) r1c1Part(i int) (int) {
+    s := lx.s
+    axis := func(j int, letter byte) (int) {
+        if j >= len(s) || s[j] | 0x20 != letter {
+            return j
+        }
+        j ++
+        if j < len(s) && s[j] == '[' {
+            if k := strings.IndexByte(s[j:], ']'); k != -1 {
+                return j + k + 1
+            }
+            return j
+        }
+        for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+            j ++
+        }
+        return j
+    }
+    j := axis(axis(i, 'r'), 'c')
+    if j == i {
+        return -1
+    }
+    return j
+}
+
+//spaces between operands are the intersection operator of their last space, others are Space of the next token.
+func (lx *formulaLexer) spaces() (tokens []FormulaToken) {
+    space := ""
+    for i, tok := range lx.tokens {
+        if tok.Kind != tokenSpace {
+            tok.Space, space = space, ""
+            tokens = append(tokens, tok)
+            continue
+        }
+        if i > 0 && i+1 < len(lx.tokens) {
+            prev, next := lx.tokens[i-1], lx.tokens[i+1]
+            if (prev.Kind == TokenRef || prev.Kind == TokenClose) && (next.Kind == TokenRef || next.Kind == TokenFunc || next.Kind == TokenOpen) {
+                last := len(tok.Text) - 1
+                tok.Kind, tok.Space, tok.Text, tok.Pos = TokenOperator, tok.Text[:last], " ", tok.Pos+last
+                tokens = append(tokens, tok)
+                continue
+            }
+        }
+        space = tok.Text
+    }
+    return
+}
+
+//precedences of binary operators, % is postfix.
+var formulaPrecedence = map[string]int{
+    ":": 10, " ": 9, ",": 8,
+    "%": 6, "^": 5, "*": 4, "/": 4, "+": 3, "-": 3, "&": 2,
+    "=": 1, "<>": 1, "<": 1, ">": 1, "<=": 1, ">=": 1,
+}
+
+//the precedence of the operands of - + and @.
+const unaryPrecedence = 7
+
+//formulaParser builds the syntax tree of tokens by precedence climbing.
+type formulaParser struct {
+    s      string
+    tokens []FormulaToken
+    i      int
+}
+
+//
+func parseFormula(formula string, tokens []FormulaToken) (*Formula, error) {
+    p := &formulaParser{s: formula, tokens: tokens}
+    if len(tokens) == 0 {
+        return nil, formulaError(formula, 0, "empty")
+    }
+    root, err := p.expr(0, false)
+    if err != nil {
+        return nil, err
+    }
+    if tok := p.peek(); tok != nil {
+        return nil, p.err(tok, "unexpected "+tok.Text)
+    }
+    last := tokens[len(tokens)-1]
+    return &Formula{Root: root, Space: formula[last.Pos+len(last.Text):]}, nil
+}
+
+//
+func (p *formulaParser) peek() (*FormulaToken) {
+    if p.i < len(p.tokens) {
+        return &p.tokens[p.i]
+    }
+    return nil
+}
+
+//
+func (p *formulaParser) err(tok *FormulaToken, reason string) (error) {
+    if tok == nil {
+        return formulaError(p.s, len(p.s), reason)
+    }
+    return formulaError(p.s, tok.Pos, reason)
+}
+
+//an expression of operators whose precedences are at least min, "," is union in parentheses.
+func (p *formulaParser) expr(min int, union bool) (left *FormulaNode, err error) {
+    if left, err = p.unary(union); err != nil {
+        return
+    }
+    for {
+        tok := p.peek()
+        if tok == nil || ! (tok.Kind == TokenOperator || tok.Kind == TokenSep && union) {
+            return
+        }
+        if tok.Text == "#" {
+            p.i ++
+            left = &FormulaNode{Kind: NodePostfix, Text: "#", Args: []*FormulaNode{left}, Spaces: []string{tok.Space}}
+            continue
+        }
+        prec, ok := formulaPrecedence[tok.Text]
+        if ! ok {
+            return nil, p.err(tok, "unexpected "+tok.Text)
+        }
+        if prec < min {
+            return
+        }
+        p.i ++
+        if tok.Text == "%" {
+            left = &FormulaNode{Kind: NodePostfix, Text: "%", Args: []*FormulaNode{left}, Spaces: []string{tok.Space}}
+            continue
+        }
+        right, err := p.expr(prec+1, union)
+        if err != nil {
+            return nil, err
+        }
+        left = &FormulaNode{Kind: NodeBinary, Text: tok.Text, Args: []*FormulaNode{left, right}, Spaces: []string{tok.Space}}
+    }
+}
+
+//
+func (p *formulaParser) unary(union bool) (*FormulaNode, error) {
+    tok := p.peek()
+    if tok != nil && tok.Kind == TokenOperator && (tok.Text == "-" || tok.Text == "+" || tok.Text == "@") {
+        p.i ++
+        arg, err := p.expr(unaryPrecedence, union)
+        if err != nil {
+            return nil, err
+        }
+        return &FormulaNode{Kind: NodeUnary, Text: tok.Text, Args: []*FormulaNode{arg}, Spaces: []string{tok.Space}}, nil
+    }
+    return p.primary()
+}
+
+//
+func (p *formulaParser) primary() (*FormulaNode, error) {
+    tok := p.peek()
+    if tok == nil {
+        return nil, p.err(nil, "unexpected end")
+    }
+    p.i ++
+    spaces := []string{tok.Space}
+    switch tok.Kind {
+        case TokenNumber:
+            return &FormulaNode{Kind: NodeNumber, Text: tok.Text, Spaces: spaces}, nil
+        case TokenString:
+            return &FormulaNode{Kind: NodeString, Text: strings.Replace(tok.Text[1:len(tok.Text)-1], `""`, `"`, -1), Spaces: spaces}, nil
+        case TokenBool:
+            return &FormulaNode{Kind: NodeBool, Text: strings.ToUpper(tok.Text), Spaces: spaces}, nil
+        case TokenError:
+            return &FormulaNode{Kind: NodeError, Text: strings.ToUpper(tok.Text), Spaces: spaces}, nil
+        case TokenRef:
+            ref := *tok.Ref
+            return &FormulaNode{Kind: NodeRef, Ref: &ref, Spaces: spaces}, nil
+        case TokenFunc:
+            return p.function(tok)
+        case TokenOpen:
+            inner, err := p.expr(0, true)
+            if err != nil {
+                return nil, err
+            }
+            close := p.peek()
+            if close == nil || close.Kind != TokenClose {
+                return nil, p.err(close, "want )")
+            }
+            p.i ++
+            return &FormulaNode{Kind: NodeParen, Args: []*FormulaNode{inner}, Spaces: append(spaces, close.Space)}, nil
+        case TokenArrayOpen:
+            return p.array(tok)
+    }
+    return nil, p.err(tok, "unexpected "+tok.Text)
+}
+
+//the arguments of a function after its name, omitted ones are NodeMissing.
+func (p *formulaParser) function(name *FormulaToken) (*FormulaNode, error) {
+    node := &FormulaNode{Kind: NodeFunc, Text: name.Text, Spaces: []string{name.Space, p.peek().Space}}
+    p.i ++
+    if tok := p.peek(); tok != nil && tok.Kind == TokenClose {
+        p.i ++
+        node.Spaces = append(node.Spaces, tok.Space)
+        return node, nil
+    }
+    for {
+        tok := p.peek()
+        if tok == nil {
+            return nil, p.err(nil, "unclosed "+name.Text+"(")
+        }
+        arg := &FormulaNode{Kind: NodeMissing}
+        if tok.Kind != TokenSep && tok.Kind != TokenClose {
+            var err error
+            if arg, err = p.expr(0, false); err != nil {
+                return nil, err
+            }
+        }
+        node.Args = append(node.Args, arg)
+        switch tok = p.peek(); {
+            case tok == nil:
+                return nil, p.err(nil, "unclosed "+name.Text+"(")
+            case tok.Kind == TokenClose:
+                p.i ++
+                node.Spaces = append(node.Spaces, tok.Space)
+                return node, nil
+            case tok.Kind != TokenSep:
+                return nil, p.err(tok, "want , or )")
+        }
+        node.Spaces = append(node.Spaces, tok.Space)
+        p.i ++
+    }
+}
+
+//the rows of an array after {.
+func (p *formulaParser) array(open *FormulaToken) (*FormulaNode, error) {
+    node := &FormulaNode{Kind: NodeArray, Rows: [][]*FormulaNode{{}}, Spaces: []string{open.Space}}
+    for {
+        elem, err := p.expr(0, false)
+        if err != nil {
+            return nil, err
+        }
+        row := len(node.Rows) - 1
+        node.Rows[row] = append(node.Rows[row], elem)
+        tok := p.peek()
+        switch {
+            case tok == nil:
+                return nil, p.err(nil, "unclosed {")
+            case tok.Kind == TokenArrayClose:
+                p.i ++
+                node.Spaces = append(node.Spaces, tok.Space)
+                return node, nil
+            case tok.Kind == TokenRowSep:
+                node.Rows = append(node.Rows, []*FormulaNode{})
+            case tok.Kind != TokenSep:
+                return nil, p.err(tok, "want , ; or }")
+        }
+        node.Spaces = append(node.Spaces, tok.Space)
+        p.i ++
+    }
+}
+
+//String formats the formula in A1 notation with "=", and with the whitespace of it.
+func (f *Formula) String() (string) {
+    return "="+f.Root.String()+f.Space
+}
+
+//R1C1 formats the formula in R1C1 notation with "=", relative references are of the cell base.
+func (f *Formula) R1C1(base CellRef) (string) {
+    return "="+f.Root.R1C1(base)+f.Space
+}
+
+//String formats the node in A1 notation.
+func (node *FormulaNode) String() (string) {
+    var b strings.Builder
+    node.format(&b, false, CellRef{})
+    return b.String()
+}
+
+//R1C1 formats the node in R1C1 notation, relative references are of the cell base.
+func (node *FormulaNode) R1C1(base CellRef) (string) {
+    var b strings.Builder
+    node.format(&b, true, base)
+    return b.String()
+}
+
+//
+func (node *FormulaNode) format(b *strings.Builder, r1c1 bool, base CellRef) {
+    token := 0
+    write := func(s string) {
+        if token < len(node.Spaces) {
+            b.WriteString(node.Spaces[token])
+        }
+        token ++
+        b.WriteString(s)
+    }
+    switch node.Kind {
+        case NodeString:
+            write(`"`+strings.Replace(node.Text, `"`, `""`, -1)+`"`)
+        case NodeRef:
+            write(node.Ref.format(r1c1, base))
+        case NodeFunc:
+            write(node.Text)
+            write("(")
+            for i, arg := range node.Args {
+                if i > 0 {
+                    write(",")
+                }
+                arg.format(b, r1c1, base)
+            }
+            write(")")
+        case NodeUnary:
+            write(node.Text)
+            node.Args[0].format(b, r1c1, base)
+        case NodeBinary:
+            node.Args[0].format(b, r1c1, base)
+            write(node.Text)
+            node.Args[1].format(b, r1c1, base)
+        case NodePostfix:
+            node.Args[0].format(b, r1c1, base)
+            write(node.Text)
+        case NodeParen:
+            write("(")
+            node.Args[0].format(b, r1c1, base)
+            write(")")
+        case NodeArray:
+            write("{")
+            for i, row := range node.Rows {
+                if i > 0 {
+                    write(";")
+                }
+                for j, elem := range row {
+                    if j > 0 {
+                        write(",")
+                    }
+                    elem.format(b, r1c1, base)
+                }
+            }
+            write("}")
+        case NodeMissing:
+        default:
+            write(node.Text)
+    }
+}
+
+//String formats the reference in A1 notation.
+func (ref *FormulaRef) String() (string) {
+    return ref.format(false, CellRef{})
+}
+
+//
+func (ref *FormulaRef) format(r1c1 bool, base CellRef) (string) {
+    s := ref.prefix()
+    switch {
+        case ref.Invalid:
+            return s+"#REF!"
+        case ref.Spec != "":
+            return s+ref.Table+ref.Spec
+        case ref.Name != "":
+            return s+ref.Name
+        case r1c1:
+            return s+RangeRef{From: ref.Range.From, To: ref.Range.To}.R1C1(base)
+    }
+    return s+RangeRef{From: ref.Range.From, To: ref.Range.To}.String()
+}
+
+//the workbook and sheets before !, quoted if needed.
+func (ref *FormulaRef) prefix() (string) {
+    if ref.Book == "" && ref.Sheet == "" {
+        return ""
+    }
+    quote := ref.Path != "" || ref.Sheet != "" && needQuote(ref.Sheet) || ref.Sheet2 != "" && needQuote(ref.Sheet2)
+    s := ref.Sheet
+    if ref.Sheet2 != "" {
+        s += ":"+ref.Sheet2
+    }
+    if ref.Book != "" {
+        quote = quote || strings.Trim(ref.Book, "0123456789") != "" && needQuote(ref.Book)
+        s = ref.Path+"["+ref.Book+"]"+s
+    }
+    if quote {
+        s = "'"+strings.Replace(s, "'", "''", -1)+"'"
+    }
+    return s+"!"
+}
+
+//Walk calls fn for the nodes in depth-first order, the children of a node are skipped if fn returns false.
+func (node *FormulaNode) Walk(fn func(*FormulaNode) bool) {
+    if ! fn(node) {
+        return
+    }
+    for _, arg := range node.Args {
+        arg.Walk(fn)
+    }
+    for _, row := range node.Rows {
+        for _, elem := range row {
+            elem.Walk(fn)
+        }
+    }
+}
+
+//Refs returns the references of the formula in order.
+func (f *Formula) Refs() (refs []*FormulaRef) {
+    f.Root.Walk(func(node *FormulaNode) bool {
+        if node.Kind == NodeRef {
+            refs = append(refs, node.Ref)
+        }
+        return true
+    })
+    return
+}
+
+//a reference to cells, not a name, table or #REF!.
+func (ref *FormulaRef) cells() (bool) {
+    return ! ref.Invalid && ref.Name == "" && ref.Spec == ""
+}
+
+//the reference is to cells of the sheet in this workbook, not of a 3-D reference.
+func (f *Formula) onSheet(ref *FormulaRef, sheet string) (bool) {
+    own := ref.Sheet
+    if own == "" {
+        own = f.Sheet
+    }
+    return ref.cells() && ref.Book == "" && ref.Sheet2 == "" && strings.EqualFold(own, sheet)
+}
+
+//ShiftRows moves references to the sheet as Excel does for inserting n rows before row at, or deleting -n rows from it,
+//references to deleted cells are #REF!, ranges shrink or grow, sheet "" is of references without a sheet if Sheet is "".
+func (f *Formula) ShiftRows(sheet string, at int, n int) {
+    for _, ref := range f.Refs() {
+        if f.onSheet(ref, sheet) && ! shiftAxis(&ref.Range.From.Row, &ref.Range.To.Row, at, n, MaxRows) {
+            ref.Invalid = true
+        }
+    }
+}
+
+//ShiftColumns moves references to the sheet as Excel does for inserting n columns before column at, or deleting -n columns from it.
+func (f *Formula) ShiftColumns(sheet string, at int, n int) {
+    for _, ref := range f.Refs() {
+        if f.onSheet(ref, sheet) && ! shiftAxis(&ref.Range.From.Column, &ref.Range.To.Column, at, n, MaxColumns) {
+            ref.Invalid = true
+        }
+    }
+}
+
+//shift rows or columns of a range, 0 is a whole column or row which is not shifted, false if it is deleted.
+func shiftAxis(from, to *int, at int, n int, max int) (bool) {
+    if *from == 0 || n == 0 {
+        return true
+    }
+    lo, hi := from, to
+    if *lo > *hi {
+        lo, hi = hi, lo
+    }
+    if n > 0 {
+        if *lo >= at {
+            *lo += n
+        }
+        if *hi >= at {
+            *hi += n
+        }
+        if *hi > max {
+            *hi = max
+        }
+        return *lo <= max
+    }
+    last := at - n - 1
+    if *lo >= at && *hi <= last {
+        return false
+    }
+    if *lo > last {
+        *lo += n
+    } else if *lo >= at {
+        *lo = at
+    }
+    if *hi > last {
+        *hi += n
+    } else if *hi >= at {
+        *hi = at - 1
+    }
+    return true
+}
+
+//Rebase moves the relative references as Excel does for copying the formula from the cell from to the cell to,
+//references moved out of the sheet are #REF!.
+func (f *Formula) Rebase(from CellRef, to CellRef) {
+    rows, columns := to.Row - from.Row, to.Column - from.Column
+    move := func(ref *CellRef) (bool) {
+        if ref.Row > 0 && ! ref.AbsRow {
+            if ref.Row += rows; ref.Row < 1 || ref.Row > MaxRows {
+                return false
+            }
+        }
+        if ref.Column > 0 && ! ref.AbsColumn {
+            if ref.Column += columns; ref.Column < 1 || ref.Column > MaxColumns {
+                return false
+            }
+        }
+        return true
+    }
+    for _, ref := range f.Refs() {
+        if ref.cells() && (! move(&ref.Range.From) || ! move(&ref.Range.To)) {
+            ref.Invalid = true
+        }
+    }
+}
+
+//RenameSheet renames the sheet old in references of this workbook and in Sheet, as Excel does for renaming a sheet.
+func (f *Formula) RenameSheet(old string, new string) {
+    if strings.EqualFold(f.Sheet, old) {
+        f.Sheet = new
+    }
+    for _, ref := range f.Refs() {
+        if ref.Book != "" {
+            continue
+        }
+        if strings.EqualFold(ref.Sheet, old) {
+            ref.Sheet = new
+        }
+        if strings.EqualFold(ref.Sheet2, old) {
+            ref.Sheet2 = new
+        }
+    }
+}
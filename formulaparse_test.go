@@ -0,0 +1,248 @@
+package excel
+
+import (
+    "errors"
+    "testing"
+)
+
+func TestFormulaRoundTrip(t *testing.T) {
+    for _, formula := range []string{
+        "=SUM(A1:B2)*2",
+        "=IF(A1>=10,\"big \"\"x\"\"\",-B$2%)",
+        "=-2^2",
+        "='My Sheet'!$A$1+Sheet2!C:C+SUM(3:5)",
+        "=[Book1.xlsx]Sheet1!A1+[1]Sheet1!B2",
+        "='C:\\Data\\[My Book.xlsx]Sales Q1'!A1",
+        "=SUM(Sheet1:Sheet3!A1)",
+        "=SUM('Jan 1:Dec 31'!B2:B9)",
+        "=Sales[[#Totals],[Amount]]+[@Qty]*Sales[Price]",
+        "=SUM(Sales[[#This Row],[Jan]:[Mar]])",
+        "=SUM((A1,B2))",
+        "=SUM(A1:B5 B2:C3)",
+        "={1,2;3,\"x\"}",
+        "=IF(A1,,1)",
+        "=_xlfn.XLOOKUP(A1,B:B,C:C)",
+        "=A1#",
+        "=@A1:A5",
+        "=Sheet1!#REF!+#N/A",
+        "=TRUE<>FALSE",
+        "=1.5E+3&.5",
+        "=Total*Sheet2!Rate",
+        "=NOW()",
+        "=A1:INDEX(B:B,3)",
+        "=LOG10(100)",
+    } {
+        f, err := ParseFormula(formula)
+        if err != nil {
+            t.Errorf("ParseFormula(%q): %v", formula, err)
+            continue
+        }
+        if got := f.String(); got != formula {
+            t.Errorf("ParseFormula(%q) is printed as %q", formula, got)
+        }
+    }
+}
+
+func TestFormulaSpaces(t *testing.T) {
+    for _, formula := range []string{
+        "=A1 + B1",
+        "= SUM( A1 , B2 ) ",
+        "=IF(A1>1,\n  \"x\",\r\n\t-B2 %)",
+        "=SUM(A1:B5  B2:C3)",
+        "=( A1 ,B2 )",
+        "={ 1 , 2 ; 3 , 4 }",
+        "=IF(A1, ,1)",
+        "= NOW( )",
+        "=@ A1# ",
+    } {
+        f, err := ParseFormula(formula)
+        if err != nil {
+            t.Errorf("ParseFormula(%q): %v", formula, err)
+            continue
+        }
+        if got := f.String(); got != formula {
+            t.Errorf("ParseFormula(%q) is printed as %q", formula, got)
+        }
+    }
+    f, _ := ParseFormula("=SUM( A1 , B2 ) + Sheet1!C3")
+    f.ShiftRows("", 10, 1)
+    f.Rebase(CellRef{Row: 1, Column: 1}, CellRef{Row: 1, Column: 1})
+    f.RenameSheet("Other", "New")
+    if got := f.String(); got != "=SUM( A1 , B2 ) + Sheet1!C3" {
+        t.Fatalf("a formula of unchanged references is printed as %q", got)
+    }
+    f.ShiftRows("", 2, 1)
+    f.RenameSheet("Sheet1", "Data")
+    if got := f.String(); got != "=SUM( A1 , B3 ) + Data!C3" {
+        t.Fatalf("the shifted formula is %q", got)
+    }
+    if got := f.R1C1(CellRef{Row: 1, Column: 1}); got != "=SUM( RC , R[2]C[1] ) + Data!R[2]C[2]" {
+        t.Fatalf("the shifted formula in R1C1 is %q", got)
+    }
+    if node := (&FormulaNode{Kind: NodeBinary, Text: "+", Args: f.Root.Args}); node.String() != "SUM( A1 , B3 )+ Data!C3" {
+        t.Fatalf("a node without Spaces is %q", node)
+    }
+}
+
+func TestFormulaTree(t *testing.T) {
+    f, _ := ParseFormula("=1+2*3^-2")
+    root := f.Root
+    if root.Kind != NodeBinary || root.Text != "+" || root.Args[1].Text != "*" || root.Args[1].Args[1].Text != "^" || root.Args[1].Args[1].Args[1].Kind != NodeUnary {
+        t.Fatalf("1+2*3^-2 is %v", root)
+    }
+    if f, _ = ParseFormula("=-2^2"); f.Root.Text != "^" || f.Root.Args[0].Kind != NodeUnary {
+        t.Fatal("- binds looser than ^")
+    }
+    if f, _ = ParseFormula("=A1 & B1 = C1"); f.Root.Text != "=" || f.Root.String() != "A1 & B1 = C1" {
+        t.Fatalf("A1 & B1 = C1 is %v", f.Root)
+    }
+    f, _ = ParseFormula("=SUM(A1:B2 B1:C3)")
+    if f.Root.Args[0].Text != " " {
+        t.Fatalf("the intersection is %q", f.Root.Args[0].Text)
+    }
+    if refs := f.Refs(); len(refs) != 2 || refs[0].Range.To.Column != 2 || refs[1].Range.From.Row != 1 {
+        t.Fatalf("refs %v", refs)
+    }
+    f, _ = ParseFormula("='[Book 1.xlsx]Sheet 2'!A1+Sales[@Qty]")
+    if ref := f.Refs()[0]; ref.Book != "Book 1.xlsx" || ref.Sheet != "Sheet 2" {
+        t.Fatalf("external ref %+v", ref)
+    }
+    if ref := f.Refs()[1]; ref.Table != "Sales" || ref.Spec != "[@Qty]" {
+        t.Fatalf("structured ref %+v", ref)
+    }
+    tokens, err := TokenizeFormula("=SUM(A1, 2)")
+    if err != nil || len(tokens) != 6 || tokens[0].Kind != TokenFunc || tokens[2].Kind != TokenRef || tokens[3].Kind != TokenSep {
+        t.Fatal(tokens, err)
+    }
+}
+
+func TestFormulaErrors(t *testing.T) {
+    for _, formula := range []string{"=SUM(A1", "=\"abc", "=1+", "=#FOO", "=(1,2", "=A1,B1", "='x", "=1 2", "=R[-5]C"} {
+        if _, err := ParseFormula(formula); ! errors.Is(err, ErrFormula) {
+            t.Errorf("ParseFormula(%q): %v", formula, err)
+        }
+    }
+    if _, err := ParseFormulaR1C1("=R[-5]C", CellRef{Row: 2, Column: 1}); ! errors.Is(err, ErrFormula) {
+        t.Errorf("R[-5]C of row 2: %v", err)
+    }
+}
+
+func TestFormulaR1C1(t *testing.T) {
+    base := CellRef{Row: 3, Column: 3}
+    tests := []struct {
+        r1c1 string
+        a1   string
+    }{
+        {"=SUM(R1C1:R[-1]C)+RC[-1]*Sheet2!C2+R2", "=SUM($A$1:C2)+B3*Sheet2!$B:$B+$2:$2"},
+        {"=R[-2]C[-2]+R1C[1]", "=A1+D$1"},
+        {"=Sheet1:Sheet3!RC+[Book.xlsx]Sheet1!R[1]C", "=Sheet1:Sheet3!C3+[Book.xlsx]Sheet1!C4"},
+    }
+    for _, test := range tests {
+        f, err := ParseFormulaR1C1(test.r1c1, base)
+        if err != nil {
+            t.Errorf("ParseFormulaR1C1(%q): %v", test.r1c1, err)
+            continue
+        }
+        if a1 := f.String(); a1 != test.a1 {
+            t.Errorf("%v is %v in A1, want %v", test.r1c1, a1, test.a1)
+        }
+        if r1c1 := f.R1C1(base); r1c1 != test.r1c1 {
+            t.Errorf("%v is printed as %v", test.r1c1, r1c1)
+        }
+    }
+}
+
+func TestFormulaShift(t *testing.T) {
+    tests := []struct {
+        formula string
+        sheet   string
+        rows    bool
+        at      int
+        n       int
+        want    string
+    }{
+        {"=SUM(A1:A10)+A5+$B$20+Sheet2!A5+C:C+5:5", "", true, 5, 2, "=SUM(A1:A12)+A7+$B$22+Sheet2!A5+C:C+7:7"},
+        {"=SUM(A1:A10)+A5+B4", "", true, 5, -1, "=SUM(A1:A9)+#REF!+B4"},
+        {"=A5:A6+5:5+A4", "", true, 5, -2, "=#REF!+#REF!+A4"},
+        {"=SUM(A1:A10)+Sheet2!B5+B5", "Sheet2", true, 1, -3, "=SUM(A1:A7)+Sheet2!B2+B2"},
+        {"=SUM(B1:D1)+E1", "", false, 2, -2, "=SUM(B1)+C1"},
+        {"=SUM(B1:D1)+E1+C:C", "", false, 3, 1, "=SUM(B1:E1)+F1+D:D"},
+        {"=[Book.xlsx]Sheet1!A5+Sheet1:Sheet3!A5+'[Book.xlsx]Sheet1'!A6", "Sheet1", true, 1, 1, "=[Book.xlsx]Sheet1!A5+Sheet1:Sheet3!A5+[Book.xlsx]Sheet1!A6"},
+        {"=Sales[Price]*A5+Sales[[#This Row],[Qty]]", "", true, 1, 1, "=Sales[Price]*A6+Sales[[#This Row],[Qty]]"},
+    }
+    for _, test := range tests {
+        f, err := ParseFormula(test.formula)
+        if err != nil {
+            t.Errorf("ParseFormula(%q): %v", test.formula, err)
+            continue
+        }
+        f.Sheet = test.sheet
+        if test.rows {
+            f.ShiftRows(test.sheet, test.at, test.n)
+        } else {
+            f.ShiftColumns(test.sheet, test.at, test.n)
+        }
+        if got := f.String(); got != test.want {
+            t.Errorf("%v shifted by %v at %v is %v, want %v", test.formula, test.n, test.at, got, test.want)
+        }
+    }
+}
+
+func TestFormulaRebase(t *testing.T) {
+    tests := []struct {
+        formula string
+        from    string
+        to      string
+        want    string
+    }{
+        {"=A1+$A1+A$1+$A$1+SUM(B:B)+Sheet1!C2", "A1", "B3", "=B3+$A3+B$1+$A$1+SUM(C:C)+Sheet1!D4"},
+        {"=A2+$A2+A$1+B:B+2:2", "A2", "A1", "=A1+$A1+A$1+B:B+1:1"},
+        {"=A1+$A1+A$1+B:B+1:1", "A2", "A1", "=#REF!+#REF!+A$1+B:B+#REF!"},
+        {"=Sheet1:Sheet3!A1+[Book.xlsx]Sheet1!A1", "A1", "C2", "=Sheet1:Sheet3!C2+[Book.xlsx]Sheet1!C2"},
+        {"=SUM(Sales[Qty])+B1", "B1", "B2", "=SUM(Sales[Qty])+B2"},
+    }
+    for _, test := range tests {
+        f, err := ParseFormula(test.formula)
+        if err != nil {
+            t.Errorf("ParseFormula(%q): %v", test.formula, err)
+            continue
+        }
+        from, _ := ParseCellRef(test.from)
+        to, _ := ParseCellRef(test.to)
+        f.Rebase(from, to)
+        if got := f.String(); got != test.want {
+            t.Errorf("%v from %v to %v is %v, want %v", test.formula, test.from, test.to, got, test.want)
+        }
+    }
+}
+
+func TestFormulaRenameSheet(t *testing.T) {
+    tests := []struct {
+        formula string
+        old     string
+        new     string
+        want    string
+    }{
+        {"=Sheet1!A1+'Sheet1'!B1+A1", "sheet1", "My Data", "='My Data'!A1+'My Data'!B1+A1"},
+        {"=Sheet1:Sheet3!A1+SUM(Jan:Sheet1!B2)", "Sheet1", "Data", "=Data:Sheet3!A1+SUM(Jan:Data!B2)"},
+        {"=[B.xlsx]Sheet1!A1+'C:\\x\\[B.xlsx]Sheet1'!A1", "Sheet1", "Data", "=[B.xlsx]Sheet1!A1+'C:\\x\\[B.xlsx]Sheet1'!A1"},
+        {"=Sheet2!A1+Sales[Qty]+Sheet1!#REF!", "Sheet1", "Data", "=Sheet2!A1+Sales[Qty]+Data!#REF!"},
+        {"=Sheet1!A1", "Sheet1", "Q1", "='Q1'!A1"},
+    }
+    for _, test := range tests {
+        f, err := ParseFormula(test.formula)
+        if err != nil {
+            t.Errorf("ParseFormula(%q): %v", test.formula, err)
+            continue
+        }
+        f.RenameSheet(test.old, test.new)
+        if got := f.String(); got != test.want {
+            t.Errorf("%v with %v renamed to %v is %v, want %v", test.formula, test.old, test.new, got, test.want)
+        }
+    }
+    f, _ := ParseFormula("=A1")
+    f.Sheet = "Sheet1"
+    if f.RenameSheet("SHEET1", "Data"); f.Sheet != "Data" {
+        t.Fatalf("Sheet is %q after renamed", f.Sheet)
+    }
+}
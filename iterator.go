@@ -0,0 +1,169 @@
+package excel
+
+import (
+    "context"
+    "errors"
+)
+
+//stops Next after Close, not reported by Err.
+var errClosed = errors.New("iterator closed")
+
+//Row is a row of cells with its 1-based row number in the sheet.
+type Row struct {
+    Number int
+    Values []interface{}
+}
+
+//RowIterator reads rows chunk by chunk, like bufio.Scanner:
+//
+//    it, err := sheet.Rows(ctx, "A:F", 500)
+//    defer it.Close()
+//    for it.Next() {
+//        row := it.Row()
+//    }
+//    err = it.Err()
+type RowIterator struct {
+    ctx     context.Context
+    sheet   Sheet
+    ra      readRange
+    next    int
+    chunk   [][]interface{}
+    number  int
+    row     Row
+    err     error
+    release func()
+}
+
+//the default number of rows read once, fewer for wider ranges.
+func defaultChunk(columns int) (int) {
+    return 1000/(10+columns)+1
+}
+
+//
+func (sheet Sheet) newRowIterator(ctx context.Context, ra readRange) (*RowIterator) {
+    if ctx == nil {
+        ctx = context.Background()
+    }
+    if ra.once <= 0 {
+        ra.once = defaultChunk(ra.columnEnd-ra.columnBegin+1)
+    }
+    return &RowIterator{ctx: ctx, sheet: sheet, ra: ra, next: ra.rowBegin}
+}
+
+//Rows returns an iterator over rows of rang like "A2:F100" in the sheet, whole columns like "A:F" or rows like "2:9"
+//end at the UsedRange. chunk is the number of rows read once, 0 for a size by the columns.
+func (sheet Sheet) Rows(ctx context.Context, rang string, chunk int) (it *RowIterator, err error) {
+    ref, err := ParseRangeRef(rang)
+    if err != nil {
+        return
+    } else if ref.Sheet != "" {
+        return nil, refError(rang, "want a range of the sheet without sheet name")
+    }
+    r1, c1, r2, c2 := ref.Bounds()
+    if ref.From.Row == 0 || ref.From.Column == 0 {
+        ur, uc, e := sheet.usedEnd()
+        if e != nil {
+            return nil, e
+        }
+        if ref.From.Row == 0 && r2 > ur {
+            r2 = ur
+        }
+        if ref.From.Column == 0 && c2 > uc {
+            c2 = uc
+        }
+    }
+    return sheet.newRowIterator(ctx, readRange{columnBegin: c1, columnEnd: c2, rowBegin: r1, rowEnd: r2, once: chunk}), nil
+}
+
+//the last used row and column of the sheet.
+func (sheet Sheet) usedEnd() (row, column int, err error) {
+    defer Except("Sheet.usedEnd", &err)
+    used := Range{mustGetDispatcher(sheet.Dispatcher, "UsedRange")}
+    defer DoFuncs(used.Release)
+    r, _ := toInt(used.MustGet("Row"))
+    c, _ := toInt(used.MustGet("Column"))
+    rc, _ := toInt(used.MustGet("Rows", "Count"))
+    cc, _ := toInt(used.MustGet("Columns", "Count"))
+    return r+rc-1, c+cc-1, nil
+}
+
+//Rows returns an iterator over rows of the range, chunk is the number of rows read once, 0 for a size by the columns.
+func (rg Range) Rows(ctx context.Context, chunk int) (it *RowIterator, err error) {
+    defer Except("Range.Rows", &err)
+    ref, err := ParseRangeRef(String(rg.MustGet("Address")))
+    if err != nil {
+        return
+    }
+    sheet := Sheet{mustGetDispatcher(rg.Dispatcher, "Worksheet")}
+    r1, c1, r2, c2 := ref.Bounds()
+    it = sheet.newRowIterator(ctx, readRange{columnBegin: c1, columnEnd: c2, rowBegin: r1, rowEnd: r2, once: chunk})
+    it.release = func() {
+        sheet.Release()
+    }
+    return
+}
+
+//Next reads the next row, false at the end, on error, on cancel of the context or after Close.
+func (it *RowIterator) Next() (bool) {
+    if it.err != nil {
+        return false
+    }
+    if it.err = it.ctx.Err(); it.err != nil {
+        return false
+    }
+    if len(it.chunk) == 0 {
+        if it.next > it.ra.rowEnd || ! it.fetch() {
+            return false
+        }
+    }
+    it.row = Row{Number: it.number, Values: it.chunk[0]}
+    it.chunk, it.number = it.chunk[1:], it.number + 1
+    return true
+}
+
+//read the next chunk of rows.
+func (it *RowIterator) fetch() (bool) {
+    end := it.next + it.ra.once - 1
+    if end > it.ra.rowEnd {
+        end = it.ra.rowEnd
+    }
+    rang := RangeRef{From: CellRef{Row: it.next, Column: it.ra.columnBegin}, To: CellRef{Row: end, Column: it.ra.columnEnd}}
+    val, err := it.sheet.GetRange(rang.String())
+    if err != nil {
+        it.err = err
+        return false
+    }
+    if rows, ok := val.([][]interface{}); ok {
+        it.chunk = rows
+    } else {
+        it.chunk = [][]interface{} {{val}}
+    }
+    it.number, it.next = it.next, end + 1
+    return len(it.chunk) > 0
+}
+
+//Row returns the row read by Next.
+func (it *RowIterator) Row() (Row) {
+    return it.row
+}
+
+//Err returns the error stopping Next, nil at the end.
+func (it *RowIterator) Err() (error) {
+    if it.err == errClosed {
+        return nil
+    }
+    return it.err
+}
+
+//Close stops the iterator and releases the sheet it holds.
+func (it *RowIterator) Close() (error) {
+    if it.err == nil {
+        it.err = errClosed
+    }
+    it.chunk = nil
+    if it.release != nil {
+        it.release()
+        it.release = nil
+    }
+    return nil
+}
@@ -0,0 +1,155 @@
+package excel
+
+import (
+    "context"
+    "errors"
+    "testing"
+)
+
+//a fake sheet with n rows of (row number, "r<n>") in A:B.
+func iteratorSheet(t *testing.T, n int) (*MSO, Sheet, *FakeDispatch) {
+    mso, err := New(Option{"Backend": "fake"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    sheet, _ := mso.Sheet(1)
+    for r := 1; r <= n; r ++ {
+        sheet.PutCell(r, 1, r)
+        sheet.PutCell(r, 2, "r"+String(r))
+    }
+    return mso, sheet, unwrap(sheet.Dispatcher).(*FakeDispatch)
+}
+
+//the number of reads of Range since the last call.
+func rangeReads(fake *FakeDispatch) (n int) {
+    for _, call := range fake.Calls {
+        if call == "Get Range" {
+            n ++
+        }
+    }
+    fake.Calls = nil
+    return
+}
+
+func TestRowIteratorChunks(t *testing.T) {
+    mso, sheet, fake := iteratorSheet(t, 7)
+    defer mso.Quit()
+    tests := []struct {
+        rang   string
+        chunk  int
+        first  int
+        last   int
+        reads  int
+    }{
+        {"A:B", 3, 1, 7, 3},
+        {"A:B", 7, 1, 7, 1},
+        {"A:B", 100, 1, 7, 1},
+        {"A2:B7", 5, 2, 7, 2},
+        {"A3:B3", 1, 3, 3, 1},
+        {"2:4", 2, 2, 4, 2},
+        {"A1:B20", 0, 1, 20, 1},
+    }
+    for _, test := range tests {
+        rangeReads(fake)
+        it, err := sheet.Rows(context.Background(), test.rang, test.chunk)
+        if err != nil {
+            t.Fatal(err)
+        }
+        number := test.first
+        for it.Next() {
+            row := it.Row()
+            want := interface{}(float64(row.Number))
+            if row.Number > 7 {
+                want = ""
+            }
+            if row.Number != number || len(row.Values) != 2 || row.Values[0] != want {
+                t.Errorf("%v by %v: row %v is %v %v, want row %v", test.rang, test.chunk, number, row.Number, row.Values, number)
+            }
+            number ++
+        }
+        it.Close()
+        if err = it.Err(); err != nil || number != test.last+1 {
+            t.Errorf("%v by %v: rows end before %v, want %v: %v", test.rang, test.chunk, number, test.last+1, err)
+        }
+        if reads := rangeReads(fake); reads != test.reads {
+            t.Errorf("%v by %v: %v reads, want %v", test.rang, test.chunk, reads, test.reads)
+        }
+    }
+    if _, err := sheet.Rows(context.Background(), "Sheet1!A:B", 3); ! errors.Is(err, ErrReference) {
+        t.Fatalf("a range with sheet name: %v", err)
+    }
+}
+
+func TestRowIteratorCancel(t *testing.T) {
+    mso, sheet, fake := iteratorSheet(t, 9)
+    defer mso.Quit()
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    it, err := sheet.Rows(ctx, "A:B", 4)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer it.Close()
+    rangeReads(fake)
+    for i := 0; i < 2 && it.Next(); i ++ {
+    }
+    cancel()
+    if it.Next() {
+        t.Fatalf("row %v is read after cancel", it.Row().Number)
+    }
+    if err = it.Err(); ! errors.Is(err, context.Canceled) {
+        t.Fatalf("Err is %v after cancel", err)
+    }
+    if it.Next() || rangeReads(fake) != 1 {
+        t.Fatal("rows are read after cancel")
+    }
+}
+
+func TestRowIteratorClose(t *testing.T) {
+    mso, sheet, fake := iteratorSheet(t, 5)
+    defer mso.Quit()
+    it, err := sheet.Rows(context.Background(), "A1:B5", 2)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if ! it.Next() || it.Row().Number != 1 {
+        t.Fatal("the first row is not read")
+    }
+    rangeReads(fake)
+    it.Close()
+    if it.Next() || it.Err() != nil || rangeReads(fake) != 0 {
+        t.Fatalf("Next after Close, Err is %v", it.Err())
+    }
+    it.Close()
+}
+
+func TestRangeRows(t *testing.T) {
+    mso, sheet, fake := iteratorSheet(t, 5)
+    defer mso.Quit()
+    rg := sheet.Range("B2:B4")
+    defer rg.Release()
+    released := fake.Released
+    it, err := rg.Rows(context.Background(), 2)
+    if err != nil {
+        t.Fatal(err)
+    }
+    rows := []Row{}
+    for it.Next() {
+        rows = append(rows, it.Row())
+    }
+    if err = it.Err(); err != nil || len(rows) != 3 {
+        t.Fatalf("%v rows: %v", len(rows), err)
+    }
+    for i, row := range rows {
+        if row.Number != i+2 || len(row.Values) != 1 || row.Values[0] != "r"+String(i+2) {
+            t.Errorf("row %v is %v %v", i, row.Number, row.Values)
+        }
+    }
+    if fake.Released != released {
+        t.Fatal("the sheet of the range is released before Close")
+    }
+    it.Close()
+    if fake.Released != released+1 {
+        t.Fatalf("the sheet of the range is released %v times by Close", fake.Released-released)
+    }
+}
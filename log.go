@@ -0,0 +1,72 @@
+package excel
+
+import (
+    "context"
+    "fmt"
+    "log/slog"
+    "os"
+    "runtime/debug"
+    "sync"
+)
+
+var (
+    logMutex sync.RWMutex
+    logger   = slog.New(slog.NewTextHandler(os.Stderr, nil))
+    logStack = true
+)
+
+//SetLogger routes the errors of Except to l, nil to disable logging, the default writes text to stderr.
+//an error is logged at LevelError by the operation it comes from, at LevelDebug by the operations it passes through.
+func SetLogger(l *slog.Logger) {
+    logMutex.Lock()
+    defer logMutex.Unlock()
+    logger = l
+}
+
+//SetLogStack enables or disables the stack of recovered panics in logs, enabled by default.
+func SetLogStack(on bool) {
+    logMutex.Lock()
+    defer logMutex.Unlock()
+    logStack = on
+}
+
+//log err of the operation op, recovered is true for panics, passed is true if it has been logged by an inner operation.
+func logError(op string, err error, recovered bool, passed bool) {
+    logMutex.RLock()
+    l, stack := logger, logStack
+    logMutex.RUnlock()
+    level := slog.LevelError
+    if passed {
+        level = slog.LevelDebug
+    }
+    if l == nil || ! l.Enabled(context.Background(), level) {
+        return
+    }
+    attrs := []slog.Attr{slog.String("op", op)}
+    if e, ok := err.(*Error); ok {
+        if e.Path != "" {
+            attrs = append(attrs, slog.String("path", e.Path))
+        }
+        if e.HResult != 0 {
+            attrs = append(attrs, slog.String("hresult", fmt.Sprintf("%#x", e.HResult)))
+        }
+        if e.Source != "" {
+            attrs = append(attrs, slog.String("source", e.Source))
+        }
+    }
+    attrs = append(attrs, slog.String("error", err.Error()))
+    msg := "excel error"
+    if recovered {
+        msg = "excel panic"
+        if stack {
+            attrs = append(attrs, slog.String("stack", string(debug.Stack())))
+        }
+    }
+    l.LogAttrs(context.Background(), level, msg, attrs...)
+}
+
+//an *Error with the operation has been logged by Except.
+func logged(err error) (bool) {
+    e, ok := err.(*Error)
+    return ok && e.Op != ""
+}
@@ -0,0 +1,190 @@
+package excel
+
+import (
+    "context"
+    "errors"
+    "log/slog"
+    "sync"
+    "testing"
+    "github.com/go-ole/go-ole"
+)
+
+//a slog.Handler which records the records it receives at level and above.
+type recordHandler struct {
+    mu      sync.Mutex
+    level   slog.Level
+    records []slog.Record
+}
+
+//
+func (h *recordHandler) Enabled(ctx context.Context, level slog.Level) (bool) {
+    return level >= h.level
+}
+
+//
+func (h *recordHandler) Handle(ctx context.Context, r slog.Record) (error) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    h.records = append(h.records, r.Clone())
+    return nil
+}
+
+//
+func (h *recordHandler) WithAttrs(attrs []slog.Attr) (slog.Handler) {
+    return h
+}
+
+//
+func (h *recordHandler) WithGroup(name string) (slog.Handler) {
+    return h
+}
+
+//the records since the last call.
+func (h *recordHandler) take() (records []slog.Record) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    records, h.records = h.records, nil
+    return
+}
+
+//the attributes of r by key.
+func recordAttrs(r slog.Record) (map[string]string) {
+    attrs := map[string]string{}
+    r.Attrs(func(a slog.Attr) (bool) {
+        attrs[a.Key] = a.Value.String()
+        return true
+    })
+    return attrs
+}
+
+//run f with the logger of h, then restore the default logger and stacks.
+func withLogger(h slog.Handler, f func()) {
+    logMutex.RLock()
+    saved, stack := logger, logStack
+    logMutex.RUnlock()
+    defer func() {
+        SetLogger(saved)
+        SetLogStack(stack)
+    }()
+    if h == nil {
+        SetLogger(nil)
+    } else {
+        SetLogger(slog.New(h))
+    }
+    f()
+}
+
+func TestLogErrors(t *testing.T) {
+    h := &recordHandler{level: slog.LevelDebug}
+    inner := func() (err error) {
+        defer Except("Test.Inner", &err)
+        return newError("", `Range("A1")`, ole.NewError(hrDisconnected))
+    }
+    outer := func() (err error) {
+        defer Except("Test.Outer", &err)
+        return inner()
+    }
+    withLogger(h, func() {
+        if err := outer(); ! errors.Is(err, ErrDisconnected) {
+            t.Fatal(err)
+        }
+        records := h.take()
+        if len(records) != 2 {
+            t.Fatalf("%v records, want 2", len(records))
+        }
+        attrs := recordAttrs(records[0])
+        if records[0].Level != slog.LevelError || records[0].Message != "excel error" || attrs["op"] != "Test.Inner" {
+            t.Fatalf("the first record is %v %q of %v", records[0].Level, records[0].Message, attrs["op"])
+        }
+        if attrs["path"] != `Range("A1")` || attrs["hresult"] != "0x80010108" || attrs["error"] == "" {
+            t.Fatalf("attributes %v", attrs)
+        }
+        if _, ok := attrs["stack"]; ok {
+            t.Fatal("an error has a stack")
+        }
+        if attrs = recordAttrs(records[1]); records[1].Level != slog.LevelDebug || attrs["op"] != "Test.Outer" {
+            t.Fatalf("the outer operation logs %v %v", records[1].Level, attrs)
+        }
+
+        h.level = slog.LevelInfo
+        outer()
+        if records = h.take(); len(records) != 1 || recordAttrs(records[0])["op"] != "Test.Inner" {
+            t.Fatalf("%v records at LevelInfo, want 1", len(records))
+        }
+    })
+}
+
+func TestLogMembers(t *testing.T) {
+    h := &recordHandler{}
+    withLogger(h, func() {
+        mso, err := New(Option{"Backend": "fake"})
+        if err != nil {
+            t.Fatal(err)
+        }
+        defer mso.Quit()
+        h.take()
+        if _, err = mso.Sheet("nope"); ! errors.Is(err, ErrSheetNotFound) {
+            t.Fatal(err)
+        }
+        records := h.take()
+        if len(records) != 1 || records[0].Level != slog.LevelError {
+            t.Fatalf("%v records", len(records))
+        }
+        attrs := recordAttrs(records[0])
+        if attrs["op"] != "Pick" || attrs["path"] != `WorkSheets("nope")` || attrs["hresult"] != "0x8002000b" {
+            t.Fatalf("attributes %v", attrs)
+        }
+    })
+}
+
+func TestLogPanics(t *testing.T) {
+    h := &recordHandler{}
+    boom := func() (err error) {
+        defer Except("Test.Boom", &err)
+        panic("boom")
+    }
+    withLogger(h, func() {
+        if err := boom(); err == nil || err.Error() != "Test.Boom: boom" {
+            t.Fatalf("%v", err)
+        }
+        records := h.take()
+        if len(records) != 1 || records[0].Message != "excel panic" || records[0].Level != slog.LevelError {
+            t.Fatalf("%v", records)
+        }
+        if attrs := recordAttrs(records[0]); attrs["op"] != "Test.Boom" || attrs["stack"] == "" {
+            t.Fatalf("attributes %v", attrs)
+        }
+
+        SetLogStack(false)
+        boom()
+        records = h.take()
+        if len(records) != 1 || records[0].Message != "excel panic" {
+            t.Fatalf("%v", records)
+        }
+        if _, ok := recordAttrs(records[0])["stack"]; ok {
+            t.Fatal("stack is logged after SetLogStack(false)")
+        }
+    })
+}
+
+func TestLogDisabled(t *testing.T) {
+    h := &recordHandler{}
+    withLogger(h, func() {
+        SetLogger(nil)
+        func() {
+            defer Except("Test.Silent", nil)
+            panic("silent")
+        }()
+        var err error
+        func() {
+            defer Except("Test.Silent", &err)
+            err = errors.New("silent")
+        }()
+        if err == nil {
+            t.Fatal("the error is lost without logger")
+        }
+    })
+    if records := h.take(); len(records) != 0 {
+        t.Fatalf("%v records without logger", len(records))
+    }
+}
@@ -0,0 +1,538 @@
+package excel
+
+import (
+    "context"
+    "math"
+    "time"
+    "strconv"
+    "strings"
+    "reflect"
+    "encoding"
+    "errors"
+    "fmt"
+)
+
+//CellError reports a cell which can not be converted to the field type, Row and Column are 1-based.
+type CellError struct {
+    Row    int
+    Column int
+    Value  interface{}
+    Type   reflect.Type
+    Err    error
+}
+
+//
+func (e *CellError) Error() (string) {
+    return fmt.Sprintf("%v%v: cannot convert %#v (%T) to %v: %v", ColumnItoa(e.Column), e.Row, e.Value, e.Value, e.Type, e.Err)
+}
+
+//
+func (e *CellError) Unwrap() (error) {
+    return e.Err
+}
+
+//CellErrors are all the cell errors of reading rows.
+type CellErrors []*CellError
+
+//
+func (es CellErrors) Error() (string) {
+    sl := []string{}
+    for i, e := range es {
+        if i == 10 {
+            sl = append(sl, fmt.Sprintf("and %v more", len(es)-i))
+            break
+        }
+        sl = append(sl, e.Error())
+    }
+    return strings.Join(sl, "; ")
+}
+
+//
+func (es CellErrors) Unwrap() (errs []error) {
+    for _, e := range es {
+        errs = append(errs, e)
+    }
+    return
+}
+
+//a struct field mapped to a column.
+type fieldMap struct {
+    index   []int
+    name    string
+    letters string //column letters of `excel:"col=A"`.
+    column  int    //1-based column of sheet, 0 if unresolved.
+}
+
+//rowCodec maps rows to struct fields by `excel:"col=A"` (column letters) or `excel:"Price"` (header), `excel:"-"` is skipped.
+//a plain tag is always a header, even if it looks like column letters as "ID" or "SKU" do, untagged fields are mapped by field name.
+//rows have a header if any field is not mapped by column letters.
+type rowCodec struct {
+    typ    reflect.Type
+    ptr    bool
+    fields []fieldMap
+    header bool
+}
+
+//
+func newRowCodec(elem reflect.Type) (codec *rowCodec, err error) {
+    codec = &rowCodec{typ: elem}
+    if elem.Kind() == reflect.Ptr {
+        codec.typ, codec.ptr = elem.Elem(), true
+    }
+    if codec.typ.Kind() != reflect.Struct {
+        return nil, fmt.Errorf("want struct or pointer to struct, got %v", elem)
+    }
+    codec.collect(codec.typ, nil)
+    if len(codec.fields) == 0 {
+        return nil, fmt.Errorf("%v has no exported field", codec.typ)
+    }
+    for i, f := range codec.fields {
+        if f.letters == "" {
+            codec.header = true
+            continue
+        }
+        col, err := ParseColumn(f.letters)
+        if err != nil {
+            return nil, fmt.Errorf("incorrect column of %v.%v: %w", codec.typ, f.name, err)
+        }
+        codec.fields[i].column = col
+    }
+    return
+}
+
+//
+func (codec *rowCodec) collect(typ reflect.Type, index []int) {
+    for i := 0; i < typ.NumField(); i ++ {
+        sf := typ.Field(i)
+        tag := strings.Split(sf.Tag.Get("excel"), ",")[0]
+        if tag == "-" || sf.PkgPath != "" && ! sf.Anonymous {
+            continue
+        }
+        idx := append(append([]int{}, index...), i)
+        if sf.Anonymous && tag == "" && sf.Type.Kind() == reflect.Struct {
+            codec.collect(sf.Type, idx)
+            continue
+        }
+        if sf.PkgPath != "" {
+            continue
+        }
+        field := fieldMap{index: idx, name: tag}
+        if strings.HasPrefix(tag, "col=") {
+            field.name, field.letters = sf.Name, strings.TrimPrefix(tag, "col=")
+        } else if tag == "" {
+            field.name = sf.Name
+        }
+        codec.fields = append(codec.fields, field)
+    }
+}
+
+//resolve the fields by the header row, which begins at column cb, names are case-insensitive.
+//fields mapped by column letters keep their columns.
+func (codec *rowCodec) setHeader(row []interface{}, cb int) (error) {
+    names := map[string]int{}
+    for i, val := range row {
+        name := strings.ToLower(strings.TrimSpace(String(val)))
+        if _, ok := names[name]; ! ok && name != "" {
+            names[name] = cb + i
+        }
+    }
+    for i, f := range codec.fields {
+        if f.letters != "" {
+            continue
+        }
+        if col, ok := names[strings.ToLower(f.name)]; ok {
+            codec.fields[i].column = col
+        } else {
+            return fmt.Errorf("column %q is not in the header", f.name)
+        }
+    }
+    return nil
+}
+
+//decode a row beginning at column cb of row r.
+func (codec *rowCodec) decode(row []interface{}, r int, cb int) (reflect.Value, CellErrors) {
+    ptr := reflect.New(codec.typ)
+    errs := CellErrors{}
+    for _, f := range codec.fields {
+        i := f.column - cb
+        if i < 0 || i >= len(row) {
+            continue
+        }
+        field := ptr.Elem().FieldByIndex(f.index)
+        if err := setValue(field, row[i]); err != nil {
+            errs = append(errs, &CellError{Row: r, Column: f.column, Value: row[i], Type: field.Type(), Err: err})
+        }
+    }
+    if codec.ptr {
+        return ptr, errs
+    }
+    return ptr.Elem(), errs
+}
+
+//
+func isEmptyRow(row []interface{}) (bool) {
+    for _, val := range row {
+        if val != nil && val != "" {
+            return false
+        }
+    }
+    return true
+}
+
+//rows are appended to *[]T, which is returned with the decoder.
+func sliceOf(dst interface{}) (reflect.Value, *rowCodec, error) {
+    rv := reflect.ValueOf(dst)
+    if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+        return rv, nil, fmt.Errorf("want pointer to slice, got %T", dst)
+    }
+    codec, err := newRowCodec(rv.Elem().Type().Elem())
+    return rv.Elem(), codec, err
+}
+
+//Unmarshal rows of Range.Get or ReadRow into dst of *[]T, see ReadRowsInto.
+func Unmarshal(rows [][]interface{}, dst interface{}) (error) {
+    slice, codec, err := sliceOf(dst)
+    if err != nil {
+        return err
+    }
+    if codec.header {
+        if len(rows) == 0 {
+            return errors.New("header row is missing")
+        }
+        if err = codec.setHeader(rows[0], 1); err != nil {
+            return err
+        }
+    }
+    errs := CellErrors{}
+    for i, row := range rows {
+        if (codec.header && i == 0) || isEmptyRow(row) {
+            continue
+        }
+        val, es := codec.decode(row, i+1, 1)
+        slice.Set(reflect.Append(slice, val))
+        errs = append(errs, es...)
+    }
+    if len(errs) > 0 {
+        return errs
+    }
+    return nil
+}
+
+//ReadRowsInto reads rows into dst of *[]T, args are the same as ReadRow without procfunc.
+//fields are mapped by `excel:"col=A"` (column letters) or `excel:"Price"` (header), with a header row first if any field is mapped by name.
+//a plain tag like `excel:"ID"` is a header, not column ID.
+//empty rows are skipped, cells which can not be converted are reported as CellErrors after all rows are read.
+func (sheet Sheet) ReadRowsInto(dst interface{}, args... interface{}) (err error) {
+    errs := CellErrors{}
+    if err = sheet.readRowsInto(dst, &errs, args...); err == nil && len(errs) > 0 {
+        err = errs
+    }
+    return
+}
+
+//
+func (sheet Sheet) readRowsInto(dst interface{}, errs *CellErrors, args... interface{}) (err error) {
+    defer Except("Sheet.ReadRowsInto", &err)
+    slice, codec, err := sliceOf(dst)
+    if err != nil {
+        return
+    }
+    ra, _ := sheet.readArgs(args...)
+    header := codec.header
+    for _, f := range codec.fields {
+        if ! header && (f.column < ra.columnBegin || f.column > ra.columnEnd) {
+            return fmt.Errorf("column %v of %v is out of %v:%v", f.letters, codec.typ, ColumnItoa(ra.columnBegin), ColumnItoa(ra.columnEnd))
+        }
+    }
+    it := sheet.newRowIterator(context.Background(), ra)
+    defer it.Close()
+    for it.Next() {
+        row := it.Row()
+        if header {
+            header = false
+            if err = codec.setHeader(row.Values, ra.columnBegin); err != nil {
+                return
+            }
+        } else if ! isEmptyRow(row.Values) {
+            val, es := codec.decode(row.Values, row.Number, ra.columnBegin)
+            slice.Set(reflect.Append(slice, val))
+            *errs = append(*errs, es...)
+        }
+    }
+    return it.Err()
+}
+
+var (
+    timeType = reflect.TypeOf(time.Time{})
+    textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+//set a value of VARIANT to the field, empty cells are zero values.
+func setValue(field reflect.Value, val interface{}) (error) {
+    if val == nil || val == "" {
+        field.Set(reflect.Zero(field.Type()))
+        return nil
+    }
+    if field.Kind() == reflect.Ptr {
+        elem := reflect.New(field.Type().Elem())
+        if err := setValue(elem.Elem(), val); err != nil {
+            return err
+        }
+        field.Set(elem)
+        return nil
+    }
+    rv := reflect.ValueOf(val)
+    if field.Kind() == reflect.Interface {
+        if ! rv.IsValid() || ! rv.Type().AssignableTo(field.Type()) {
+            return errors.New("not assignable")
+        }
+        field.Set(rv)
+        return nil
+    }
+
+    num, isnum := 0.0, false
+    switch rv.Kind() {
+        case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+            num, isnum = float64(rv.Int()), true
+        case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+            num, isnum = float64(rv.Uint()), true
+        case reflect.Float32, reflect.Float64:
+            num, isnum = rv.Float(), true
+    }
+    str, isstr := val.(string)
+    if isstr {
+        str = strings.TrimSpace(str)
+    }
+
+    if field.Type() == timeType {
+        switch {
+            case rv.Type() == timeType:
+                field.Set(rv)
+            case isnum:
+                field.Set(reflect.ValueOf(FromOADate(num, DateLocation)))
+            case isstr:
+                for _, layout := range []string {"2006-01-02 15:04:05", "2006-01-02", time.RFC3339} {
+                    if t, err := time.ParseInLocation(layout, str, DateLocation); err == nil {
+                        field.Set(reflect.ValueOf(t))
+                        return nil
+                    }
+                }
+                return errors.New("unknown date layout")
+            default:
+                return errors.New("not a date")
+        }
+        return nil
+    }
+    if field.CanAddr() && field.Addr().Type().Implements(textUnmarshalerType) {
+        return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(String(val)))
+    }
+
+    switch field.Kind() {
+        case reflect.String:
+            field.SetString(String(val))
+        case reflect.Bool:
+            switch {
+                case rv.Kind() == reflect.Bool:
+                    field.SetBool(rv.Bool())
+                case isnum:
+                    field.SetBool(num != 0)
+                case isstr:
+                    b, err := strconv.ParseBool(str)
+                    if err != nil {
+                        return err
+                    }
+                    field.SetBool(b)
+                default:
+                    return errors.New("not a bool")
+            }
+        case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+            if isstr {
+                n, err := strconv.ParseFloat(str, 64)
+                if err != nil {
+                    return err
+                }
+                num, isnum = n, true
+            }
+            if ! isnum {
+                return errors.New("not a number")
+            } else if num != math.Trunc(num) {
+                return errors.New("not an integer")
+            } else if num < -(1 << 63) || num >= 1 << 63 || field.OverflowInt(int64(num)) {
+                return errors.New("overflow")
+            }
+            field.SetInt(int64(num))
+        case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+            if isstr {
+                n, err := strconv.ParseFloat(str, 64)
+                if err != nil {
+                    return err
+                }
+                num, isnum = n, true
+            }
+            if ! isnum {
+                return errors.New("not a number")
+            } else if num != math.Trunc(num) {
+                return errors.New("not an integer")
+            } else if num < 0 || num >= 1 << 64 || field.OverflowUint(uint64(num)) {
+                return errors.New("overflow")
+            }
+            field.SetUint(uint64(num))
+        case reflect.Float32, reflect.Float64:
+            if isstr {
+                n, err := strconv.ParseFloat(str, 64)
+                if err != nil {
+                    return err
+                }
+                num, isnum = n, true
+            }
+            if ! isnum {
+                return errors.New("not a number")
+            } else if field.OverflowFloat(num) {
+                return errors.New("overflow")
+            }
+            field.SetFloat(num)
+        default:
+            if ! rv.Type().AssignableTo(field.Type()) {
+                return errors.New("unsupported type")
+            }
+            field.Set(rv)
+    }
+    return nil
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+//a cell value of the field, nil pointers and zero time are empty cells.
+func cellValue(rv reflect.Value) (interface{}) {
+    for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+        if rv.IsNil() {
+            return nil
+        }
+        rv = rv.Elem()
+    }
+    if rv.Type() == timeType {
+        if rv.Interface().(time.Time).IsZero() {
+            return nil
+        }
+        return rv.Interface()
+    }
+    if rv.Type().Implements(textMarshalerType) {
+        text, err := rv.Interface().(encoding.TextMarshaler).MarshalText()
+        if err == nil {
+            return string(text)
+        }
+    }
+    switch rv.Kind() {
+        case reflect.Bool:
+            return rv.Bool()
+        case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+            return rv.Int()
+        case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+            return rv.Uint()
+        case reflect.Float32, reflect.Float64:
+            return rv.Float()
+        case reflect.String:
+            return rv.String()
+    }
+    return String(rv.Interface())
+}
+
+//Marshal converts src to rows, with a header row of tag names first if header is true.
+//src is []T of structs or pointers to structs, fields are mapped the same as ReadRowsInto,
+//or a 2-D slice like [][]interface{} which is converted as is.
+func Marshal(src interface{}, header bool) ([][]interface{}, error) {
+    rows, _, err := marshalRows(src, header)
+    return rows, err
+}
+
+//also returns the first column if all fields are tagged by column letters, 0 if not.
+//fields are in order, or at their columns from the first one.
+func marshalRows(src interface{}, header bool) (rows [][]interface{}, first int, err error) {
+    rv := reflect.ValueOf(src)
+    if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+        return nil, 0, fmt.Errorf("want slice, got %T", src)
+    }
+    if is2D(src) {
+        rows, _, err = arrayRows(src)
+        return
+    }
+
+    elem := rv.Type().Elem()
+    codec, err := newRowCodec(elem)
+    if err != nil {
+        return
+    }
+    pos, width := make([]int, len(codec.fields)), len(codec.fields)
+    for i := range pos {
+        pos[i] = i
+    }
+    if ! codec.header {
+        last := 1
+        first = MaxColumns
+        for _, f := range codec.fields {
+            if f.column < first {
+                first = f.column
+            }
+            if f.column > last {
+                last = f.column
+            }
+        }
+        for i, f := range codec.fields {
+            pos[i] = f.column - first
+        }
+        width = last - first + 1
+    }
+    if header {
+        cells := make([]interface{}, width)
+        for i, f := range codec.fields {
+            if codec.header {
+                cells[pos[i]] = f.name
+            } else {
+                cells[pos[i]] = codec.typ.FieldByIndex(f.index).Name
+            }
+        }
+        rows = append(rows, cells)
+    }
+    for i := 0; i < rv.Len(); i ++ {
+        one := rv.Index(i)
+        if one.Kind() == reflect.Ptr {
+            if one.IsNil() {
+                rows = append(rows, make([]interface{}, width))
+                continue
+            }
+            one = one.Elem()
+        }
+        cells := make([]interface{}, width)
+        for j, f := range codec.fields {
+            cells[pos[j]] = cellValue(one.FieldByIndex(f.index))
+        }
+        rows = append(rows, cells)
+    }
+    return
+}
+
+//WriteRows writes src from the anchor cell like "B2" in one put of a 2-D array, see Marshal.
+//if all fields are tagged by column letters, they are written to their columns and only the row of anchor is used.
+func (sheet Sheet) WriteRows(anchor string, src interface{}, header bool) (err error) {
+    defer Except("Sheet.WriteRows", &err)
+    ref, err := ParseCellRef(anchor)
+    if err != nil {
+        return
+    }
+    rows, first, err := marshalRows(src, header)
+    if err != nil || len(rows) == 0 {
+        return
+    }
+    if first > 0 {
+        ref.Column = first
+    }
+    width := len(rows[0])
+    if width == 0 {
+        return
+    }
+    rg := RangeRef{From: CellRef{Row: ref.Row, Column: ref.Column}, To: CellRef{Row: ref.Row+len(rows)-1, Column: ref.Column+width-1}}
+    if rg.To.Row > MaxRows || rg.To.Column > MaxColumns {
+        return fmt.Errorf("%v rows and %v columns from %v are beyond the sheet", len(rows), width, anchor)
+    }
+    return sheet.PutRange(rg.String(), rows)
+}
@@ -0,0 +1,140 @@
+package excel
+
+import (
+    "errors"
+    "fmt"
+    "reflect"
+    "testing"
+    "time"
+)
+
+type codecItem struct {
+    ID   int     `excel:"ID"`
+    SKU  string  `excel:"SKU"`
+    Qty  int     `excel:"QTY"`
+    Memo *string `excel:"col=F"`
+    Note string
+    Skip string  `excel:"-"`
+}
+
+type codecColumns struct {
+    ID   int    `excel:"col=C"`
+    Name string `excel:"col=e"`
+}
+
+func TestRowCodecTags(t *testing.T) {
+    codec, err := newRowCodec(reflect.TypeOf(codecItem{}))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if ! codec.header {
+        t.Fatal("names are taken as column letters")
+    }
+    want := []struct {
+        name   string
+        column int
+    }{{"ID", 0}, {"SKU", 0}, {"QTY", 0}, {"Memo", 6}, {"Note", 0}}
+    if len(codec.fields) != len(want) {
+        t.Fatalf("%v fields, want %v", len(codec.fields), len(want))
+    }
+    for i, f := range codec.fields {
+        if f.name != want[i].name || f.column != want[i].column {
+            t.Errorf("field %v is %q at column %v, want %q at %v", i, f.name, f.column, want[i].name, want[i].column)
+        }
+    }
+    if codec, err = newRowCodec(reflect.TypeOf(&codecColumns{})); err != nil || codec.header || ! codec.ptr {
+        t.Fatalf("%+v, %v", codec, err)
+    }
+    type names struct {
+        ID   int    `excel:"ID"`
+        Col  int    `excel:"col=ID"`
+        Cell string `excel:"A1"`
+        Low  string `excel:"e"`
+    }
+    if codec, err = newRowCodec(reflect.TypeOf(names{})); err != nil || ! codec.header {
+        t.Fatalf("%+v, %v", codec, err)
+    }
+    for i, want := range []struct{name string; column int}{{"ID", 0}, {"Col", 9*26+4}, {"A1", 0}, {"e", 0}} {
+        if f := codec.fields[i]; f.name != want.name || f.column != want.column {
+            t.Errorf("field %v is %q at column %v, want %q at %v", i, f.name, f.column, want.name, want.column)
+        }
+    }
+    type wrong struct {
+        Wide string `excel:"col=XFE"`
+    }
+    if _, err = newRowCodec(reflect.TypeOf(wrong{})); err == nil {
+        t.Fatal("col=XFE is accepted")
+    }
+}
+
+func TestUnmarshalHeaderAndColumns(t *testing.T) {
+    rows := [][]interface{}{
+        {"sku", "Id", "Qty", "Note", "", "F"},
+        {"x-1", 1, 3.0, "a", nil, "memo"},
+        {},
+        {"x-2", "2", "abc", "b", nil, nil},
+    }
+    var items []codecItem
+    err := Unmarshal(rows, &items)
+    var es CellErrors
+    if ! errors.As(err, &es) || len(es) != 1 || es[0].Row != 4 || es[0].Column != 3 {
+        t.Fatalf("want an error of C4, got %v", err)
+    }
+    if len(items) != 2 || items[0].ID != 1 || items[0].SKU != "x-1" || items[0].Qty != 3 || items[1].ID != 2 {
+        t.Fatalf("%+v", items)
+    }
+    if items[0].Memo == nil || *items[0].Memo != "memo" || items[1].Memo != nil {
+        t.Fatalf("Memo of column F is %v, %v", items[0].Memo, items[1].Memo)
+    }
+    var cols []*codecColumns
+    if err = Unmarshal([][]interface{}{{nil, nil, 7, nil, "seven"}}, &cols); err != nil {
+        t.Fatal(err)
+    }
+    if len(cols) != 1 || cols[0].ID != 7 || cols[0].Name != "seven" {
+        t.Fatalf("%+v", cols)
+    }
+}
+
+func TestMarshalColumns(t *testing.T) {
+    rows, first, err := marshalRows([]codecColumns{{1, "a"}, {2, "b"}}, true)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if first != 3 || len(rows) != 3 || len(rows[0]) != 3 {
+        t.Fatalf("rows %v from column %v", rows, first)
+    }
+    if rows[0][0] != "ID" || rows[0][2] != "Name" || rows[2][0] != int64(2) || rows[2][2] != "b" {
+        t.Fatalf("%v", rows)
+    }
+}
+
+func TestSetValueOfInterfaces(t *testing.T) {
+    var row struct {
+        Any      interface{}
+        Stringer fmt.Stringer
+    }
+    rv := reflect.ValueOf(&row).Elem()
+    if err := setValue(rv.Field(0), 1.5); err != nil || row.Any != 1.5 {
+        t.Fatalf("%v, %v", row.Any, err)
+    }
+    if err := setValue(rv.Field(1), "text"); err == nil {
+        t.Fatal("string is set to fmt.Stringer")
+    }
+    when := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+    if err := setValue(rv.Field(1), when); err != nil || row.Stringer != when {
+        t.Fatalf("%v, %v", row.Stringer, err)
+    }
+    if err := setValue(rv.Field(1), nil); err != nil || row.Stringer != nil {
+        t.Fatalf("nil is %v, %v", row.Stringer, err)
+    }
+
+    type item struct {
+        Name fmt.Stringer `excel:"col=A"`
+    }
+    var items []item
+    err := Unmarshal([][]interface{}{{"a"}}, &items)
+    var es CellErrors
+    if ! errors.As(err, &es) || len(es) != 1 || es[0].Row != 1 || es[0].Column != 1 || len(items) != 1 {
+        t.Fatalf("want an error of A1, got %v", err)
+    }
+}
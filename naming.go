@@ -0,0 +1,133 @@
+package excel
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+)
+
+//Naming gives the file name of the i-th workbook saved by WorkBooks.SaveAsBy.
+type Naming func(i int, wb WorkBook) (string)
+
+//NameByIndex names the workbooks like "dir/report_0.xlsx", "dir/report_1.xlsx" by full "dir/report.xlsx".
+func NameByIndex(full string) (Naming) {
+    return func(i int, wb WorkBook) (string) {
+        ext := filepath.Ext(full)
+        return strings.TrimSuffix(full, ext)+"_"+strconv.Itoa(i)+ext
+    }
+}
+
+//NameByWorkBook names the workbooks by their Name in dir, ext like ".xlsx" replaces their extension if it is not empty.
+func NameByWorkBook(dir string, ext string) (Naming) {
+    return func(i int, wb WorkBook) (string) {
+        name := wb.Name()
+        if ext != "" {
+            name = strings.TrimSuffix(name, filepath.Ext(name))+"."+strings.TrimPrefix(ext, ".")
+        }
+        return filepath.Join(dir, name)
+    }
+}
+
+//ConflictResolution is what WorkBooks.SaveAsBy does when the file of a workbook exists,
+//or is the file of a workbook saved before.
+type ConflictResolution int
+
+//values of ConflictResolution.
+const (
+    ConflictError     ConflictResolution = iota  //the workbook is not saved, its error matches ErrFileExists
+    ConflictOverwrite                            //the existing file is overwritten, files of the same call are not
+    ConflictRename                               //saved as "name (2).xlsx" and so on
+
+    conflictByExcel   ConflictResolution = -1    //of WorkBooks.SaveAs, files are not checked, Excel asks by DisplayAlerts
+)
+
+//SaveAsBy saves the workbooks as the files named by naming, args are the other arguments of WorkBook.SaveAs.
+//errs are of the workbooks which are not saved, by their FullName before saving, empty if all are saved.
+func (wbs WorkBooks) SaveAsBy(naming Naming, conflict ConflictResolution, args... interface{}) (errs map[string]error) {
+    errs = map[string]error{}
+    taken := map[string]bool{}
+    for i, wb := range wbs {
+        full := wb.FullName()
+        if err := wb.saveAsBy(i, naming, conflict, taken, args); err != nil {
+            errs[full] = err
+        }
+    }
+    return
+}
+
+//
+func (wb WorkBook) saveAsBy(i int, naming Naming, conflict ConflictResolution, taken map[string]bool, args []interface{}) (err error) {
+    defer Except("WorkBooks.SaveAs", &err)
+    names, version := fileFormatNames(), 0.0
+    if wb.MSO != nil {
+        names, version = wb.FILEFORMAT, wb.Version
+    }
+    args, err = saveAsArgs(append([]interface{}{naming(i, wb)}, args...), names, version)
+    if err != nil {
+        return
+    }
+    full := args[0].(string)
+    if conflict == conflictByExcel {
+        return wb.SaveAs(args...)
+    }
+    exists := fileExists(full)
+    switch {
+        case taken[fileKey(full)]:
+            if conflict != ConflictRename {
+                return fmt.Errorf("%v: %w, saved by another workbook", full, ErrFileExists)
+            }
+            full = uniqueName(full, taken)
+        case exists && conflict == ConflictError:
+            return fmt.Errorf("%v: %w", full, ErrFileExists)
+        case exists && conflict == ConflictRename:
+            full = uniqueName(full, taken)
+        case exists && conflict == ConflictOverwrite:
+            if wb.MSO != nil {
+                defer wb.MSO.quietAlerts()()
+            }
+        case exists:
+            return fmt.Errorf("incorrect ConflictResolution %v", conflict)
+    }
+    taken[fileKey(full)] = true
+    args[0] = full
+    return wb.SaveAs(args...)
+}
+
+//
+func fileExists(full string) (bool) {
+    _, err := os.Stat(full)
+    return err == nil
+}
+
+//file names are compared as Windows does, ignoring case.
+func fileKey(full string) (string) {
+    if abs, err := filepath.Abs(full); err == nil {
+        full = abs
+    }
+    return strings.ToLower(full)
+}
+
+//"name (2).xlsx" and so on, which neither exists nor is taken.
+func uniqueName(full string, taken map[string]bool) (string) {
+    ext := filepath.Ext(full)
+    for n := 2; ; n ++ {
+        one := strings.TrimSuffix(full, ext)+" ("+strconv.Itoa(n)+")"+ext
+        if ! taken[fileKey(one)] && ! fileExists(one) {
+            return one
+        }
+    }
+}
+
+//DisplayAlerts is off until the returned func is called, so Excel overwrites a file without asking.
+func (mso *MSO) quietAlerts() (func()) {
+    old, err := mso.IdExcel.GetProperty("DisplayAlerts")
+    if err != nil || old == false {
+        return func() {}
+    }
+    mso.IdExcel.PutProperty("DisplayAlerts", false)
+    return func() {
+        mso.IdExcel.PutProperty("DisplayAlerts", old)
+    }
+}
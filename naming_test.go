@@ -0,0 +1,178 @@
+package excel
+
+import (
+    "errors"
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+//an xlsx instance of n workbooks, A1 of the first sheet of each is its Name.
+func namingBooks(t *testing.T, n int) (*MSO) {
+    mso, err := New(Option{"Backend": "xlsx"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    for i := 1; i < n; i ++ {
+        if _, err = mso.AddWorkBook(); err != nil {
+            mso.Quit()
+            t.Fatal(err)
+        }
+    }
+    for _, wb := range mso.WorkBooks() {
+        wb.Activate()
+        sheet, _ := mso.Sheet(1)
+        sheet.PutCell(1, 1, wb.Name())
+    }
+    return mso
+}
+
+//A1 of the first sheet of the file full.
+func savedCell(t *testing.T, full string) (interface{}) {
+    one, err := Open(full, Option{"Backend": "xlsx"})
+    if err != nil {
+        t.Fatalf("%v: %v", full, err)
+    }
+    defer one.Quit()
+    sheet, _ := one.Sheet(1)
+    val, _ := sheet.GetCell(1, 1)
+    return val
+}
+
+func TestSaveAsByIndex(t *testing.T) {
+    mso := namingBooks(t, 2)
+    defer mso.Quit()
+    dir := t.TempDir()
+    errs := mso.WorkBooks().SaveAsBy(NameByIndex(filepath.Join(dir, "report")), ConflictError)
+    if len(errs) != 0 {
+        t.Fatalf("errors of saved workbooks: %v", errs)
+    }
+    for i, name := range []string{"Book1", "Book2"} {
+        full := filepath.Join(dir, "report_"+String(i)+".xlsx")
+        if val := savedCell(t, full); val != name {
+            t.Fatalf("A1 of %v is %v, want %v", full, val, name)
+        }
+    }
+}
+
+func TestSaveAsByConflict(t *testing.T) {
+    tests := []struct {
+        conflict ConflictResolution
+        saved    string
+        old      bool
+    }{
+        {ConflictError, "", true},
+        {ConflictRename, "Book1 (2).xlsx", true},
+        {ConflictOverwrite, "Book1.xlsx", false},
+    }
+    for _, test := range tests {
+        dir := t.TempDir()
+        full := filepath.Join(dir, "Book1.xlsx")
+        if err := os.WriteFile(full, []byte("old"), 0644); err != nil {
+            t.Fatal(err)
+        }
+        mso := namingBooks(t, 1)
+        errs := mso.WorkBooks().SaveAsBy(NameByWorkBook(dir, ".xlsx"), test.conflict)
+        mso.Quit()
+        if test.saved == "" {
+            if len(errs) != 1 || ! errors.Is(errs["Book1"], ErrFileExists) {
+                t.Fatalf("%v: errors %v, want ErrFileExists of Book1", test.conflict, errs)
+            }
+        } else if len(errs) != 0 {
+            t.Fatalf("%v: errors %v", test.conflict, errs)
+        } else if val := savedCell(t, filepath.Join(dir, test.saved)); val != "Book1" {
+            t.Fatalf("%v: A1 of %v is %v", test.conflict, test.saved, val)
+        }
+        if data, _ := os.ReadFile(full); (string(data) == "old") != test.old {
+            t.Fatalf("%v: the existing file is %q", test.conflict, data)
+        }
+    }
+}
+
+func TestSaveAsBySameName(t *testing.T) {
+    fulls := []string{filepath.Join(t.TempDir(), "same.xlsx"), filepath.Join(t.TempDir(), "same.xlsx")}
+    for _, full := range fulls {
+        mso := namingBooks(t, 1)
+        sheet, _ := mso.Sheet(1)
+        sheet.PutCell(1, 1, full)
+        err := mso.WorkBook.SaveAs(full)
+        mso.Quit()
+        if err != nil {
+            t.Fatal(err)
+        }
+    }
+    for _, test := range []struct {
+        conflict ConflictResolution
+        errs     int
+    }{{ConflictError, 1}, {ConflictRename, 0}} {
+        mso, err := Open(fulls[0], Option{"Backend": "xlsx"})
+        if err != nil {
+            t.Fatal(err)
+        }
+        if _, err = mso.OpenWorkBook(fulls[1]); err != nil {
+            mso.Quit()
+            t.Fatal(err)
+        }
+        dir := t.TempDir()
+        errs := mso.WorkBooks().SaveAsBy(NameByWorkBook(dir, ""), test.conflict)
+        mso.Quit()
+        if len(errs) != test.errs {
+            t.Fatalf("%v: errors %v, want %v", test.conflict, errs, test.errs)
+        }
+        if test.errs > 0 && ! errors.Is(errs[fulls[1]], ErrFileExists) {
+            t.Fatalf("%v: errors %v, want ErrFileExists of %v", test.conflict, errs, fulls[1])
+        }
+        if val := savedCell(t, filepath.Join(dir, "same.xlsx")); val != fulls[0] {
+            t.Fatalf("%v: same.xlsx is saved from %v", test.conflict, val)
+        }
+        if test.conflict == ConflictRename {
+            if val := savedCell(t, filepath.Join(dir, "same (2).xlsx")); val != fulls[1] {
+                t.Fatalf("same (2).xlsx is saved from %v", val)
+            }
+        }
+    }
+}
+
+//the puts of DisplayAlerts since the last call.
+func alertPuts(mso *MSO) (n int) {
+    app := unwrap(mso.IdExcel).(*FakeDispatch)
+    for _, call := range app.Calls {
+        if call == "Put DisplayAlerts" {
+            n ++
+        }
+    }
+    app.Calls = nil
+    return
+}
+
+func TestWorkBooksSaveAs(t *testing.T) {
+    dir := t.TempDir()
+    full := filepath.Join(dir, "r.xlsx")
+    if err := os.WriteFile(full, []byte("old"), 0644); err != nil {
+        t.Fatal(err)
+    }
+    mso := namingBooks(t, 1)
+    defer mso.Quit()
+    mso.IdExcel.PutProperty("DisplayAlerts", true)
+    alertPuts(mso)
+    if errs := mso.SaveAs(full); len(errs) != 0 {
+        t.Fatal(errs)
+    }
+    if n := alertPuts(mso); n != 0 {
+        t.Fatalf("DisplayAlerts is put %v times by SaveAs", n)
+    }
+    sheet, _ := mso.Sheet(1)
+    sheet.PutCell(1, 1, "again")
+    if errs := mso.SaveAs(); len(errs) != 0 {
+        t.Fatalf("SaveAs without arguments: %v", errs)
+    }
+    if val := savedCell(t, full); val != "again" {
+        t.Fatalf("A1 is %v after SaveAs without arguments", val)
+    }
+    if errs := mso.WorkBooks().SaveAsBy(NameByWorkBook(dir, ""), ConflictOverwrite); len(errs) != 0 {
+        t.Fatal(errs)
+    }
+    if n := alertPuts(mso); n != 2 {
+        t.Fatalf("DisplayAlerts is put %v times by ConflictOverwrite, want off and back", n)
+    }
+}
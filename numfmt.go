@@ -0,0 +1,1108 @@
+package excel
+
+import (
+    "errors"
+    "fmt"
+    "math"
+    "strconv"
+    "strings"
+    "time"
+)
+
+//ErrNumberFormat is wrapped by the errors of parsing number formats.
+var ErrNumberFormat = errors.New("incorrect number format")
+
+//NumberFormat is a parsed number format code like "#,##0.00;[Red]-#,##0.00" or "yyyy-mm-dd hh:mm",
+//which renders values as Excel displays them.
+type NumberFormat struct {
+    Code     string
+    Date1904 bool       //serials of dates are of the 1904 date system
+    sections []nfSection
+}
+
+//kinds of tokens.
+const (
+    nfLiteral = iota
+    nfDigit             //0, # or ?
+    nfPoint
+    nfComma
+    nfPercent
+    nfExp               //E+ or E-
+    nfSlash
+    nfText              //@
+    nfGeneral
+    nfDate              //y, m, d, h, s, and n for minutes
+    nfElapsed           //[h], [mm], [ss]
+    nfAmPm              //AM/PM or A/P
+    nfSubsec            //0s after a point after seconds
+)
+
+//kinds of sections.
+const (
+    nfNumber = iota
+    nfScientific
+    nfFraction
+    nfDateTime
+    nfGeneralNumber
+    nfTextOnly
+)
+
+//
+type nfToken struct {
+    kind int
+    text string
+}
+
+//nfSection is one of the sections separated by ";".
+type nfSection struct {
+    tokens    []nfToken
+    color     string
+    cond      *nfCond
+    kind      int
+    percent   int
+    scale     int       //thousands by trailing commas
+    thousands bool
+    ampm      bool
+    subsec    int
+    text      bool      //has @
+    slash     int       //tokens of fractions
+    numFrom   int
+    denTo     int
+    denText   string
+}
+
+//nfCond is a condition like [>=100].
+type nfCond struct {
+    op  string
+    val float64
+}
+
+//
+func (cond *nfCond) match(v float64) (bool) {
+    if cond == nil {
+        return false
+    }
+    switch cond.op {
+        case "<":
+            return v < cond.val
+        case "<=":
+            return v <= cond.val
+        case ">":
+            return v > cond.val
+        case ">=":
+            return v >= cond.val
+        case "=":
+            return v == cond.val
+        case "<>":
+            return v != cond.val
+    }
+    return false
+}
+
+//names of the colors of sections.
+var nfColors = []string{"Black", "Blue", "Cyan", "Green", "Magenta", "Red", "White", "Yellow"}
+
+//
+func nfError(code string, reason string) (error) {
+    return fmt.Errorf("%w %q: %v", ErrNumberFormat, code, reason)
+}
+
+//ParseNumberFormat parses a number format code, "" is General.
+func ParseNumberFormat(code string) (*NumberFormat, error) {
+    parts, err := splitSections(code)
+    if err != nil {
+        return nil, err
+    }
+    if len(parts) > 4 {
+        return nil, nfError(code, "more than 4 sections")
+    }
+    nf := &NumberFormat{Code: code}
+    for _, part := range parts {
+        sec, err := parseSection(part)
+        if err != nil {
+            return nil, nfError(code, err.Error())
+        }
+        nf.sections = append(nf.sections, sec)
+    }
+    return nf, nil
+}
+
+//FormatNumber renders val by the number format code as Excel displays it, in the 1900 date system.
+func FormatNumber(val interface{}, code string) (string, error) {
+    nf, err := ParseNumberFormat(code)
+    if err != nil {
+        return "", err
+    }
+    text, _ := nf.Format(val)
+    return text, nil
+}
+
+//split code by ";" out of quotes, brackets and escapes.
+func splitSections(code string) (parts []string, err error) {
+    if code == "" {
+        return []string{"General"}, nil
+    }
+    runes := []rune(code)
+    start := 0
+    for i := 0; i < len(runes); i ++ {
+        switch runes[i] {
+            case '"':
+                j := i + 1
+                for j < len(runes) && runes[j] != '"' {
+                    j ++
+                }
+                if j == len(runes) {
+                    return nil, nfError(code, "unterminated quote")
+                }
+                i = j
+            case '[':
+                j := i + 1
+                for j < len(runes) && runes[j] != ']' {
+                    j ++
+                }
+                if j == len(runes) {
+                    return nil, nfError(code, "unterminated bracket")
+                }
+                i = j
+            case '\\', '_', '*':
+                i ++
+            case ';':
+                parts = append(parts, string(runes[start:i]))
+                start = i + 1
+        }
+    }
+    return append(parts, string(runes[start:])), nil
+}
+
+//
+func parseSection(s string) (sec nfSection, err error) {
+    runes := []rune(s)
+    add := func(kind int, text string) {
+        if kind == nfLiteral && len(sec.tokens) > 0 && sec.tokens[len(sec.tokens)-1].kind == nfLiteral {
+            sec.tokens[len(sec.tokens)-1].text += text
+            return
+        }
+        sec.tokens = append(sec.tokens, nfToken{kind, text})
+    }
+    for i := 0; i < len(runes); i ++ {
+        ch := runes[i]
+        lower := ch | 0x20
+        switch {
+            case ch == '"':
+                j := i + 1
+                for j < len(runes) && runes[j] != '"' {
+                    j ++
+                }
+                add(nfLiteral, string(runes[i+1:j]))
+                i = j
+            case ch == '\\':
+                if i+1 < len(runes) {
+                    add(nfLiteral, string(runes[i+1]))
+                    i ++
+                }
+            case ch == '_':
+                add(nfLiteral, " ")
+                i ++
+            case ch == '*':
+                i ++
+            case ch == '[':
+                j := i + 1
+                for j < len(runes) && runes[j] != ']' {
+                    j ++
+                }
+                if err = sec.bracket(string(runes[i+1:j]), add); err != nil {
+                    return
+                }
+                i = j
+            case ch == '0' || ch == '#' || ch == '?':
+                add(nfDigit, string(ch))
+            case ch == '.':
+                add(nfPoint, ".")
+            case ch == ',':
+                add(nfComma, ",")
+            case ch == '%':
+                add(nfPercent, "%")
+            case ch == '/':
+                add(nfSlash, "/")
+            case ch == '@':
+                add(nfText, "@")
+                sec.text = true
+            case lower == 'e' && i+1 < len(runes) && (runes[i+1] == '+' || runes[i+1] == '-'):
+                add(nfExp, "E"+string(runes[i+1]))
+                i ++
+            case lower == 'g' && strings.EqualFold(string(runes[i:min(i+7, len(runes))]), "general"):
+                add(nfGeneral, "General")
+                i += 6
+            case lower == 'a' && strings.EqualFold(string(runes[i:min(i+5, len(runes))]), "am/pm"):
+                add(nfAmPm, string(runes[i:i+5]))
+                sec.ampm = true
+                i += 4
+            case lower == 'a' && strings.EqualFold(string(runes[i:min(i+3, len(runes))]), "a/p"):
+                add(nfAmPm, string(runes[i:i+3]))
+                sec.ampm = true
+                i += 2
+            case lower == 'y' || lower == 'm' || lower == 'd' || lower == 'h' || lower == 's':
+                j := i
+                for j < len(runes) && runes[j] | 0x20 == lower {
+                    j ++
+                }
+                add(nfDate, strings.Repeat(string(lower), j-i))
+                i = j - 1
+            default:
+                add(nfLiteral, string(ch))
+        }
+    }
+    sec.classify()
+    return
+}
+
+//the content of brackets: a color, condition, currency, elapsed time or locale, which is ignored.
+func (sec *nfSection) bracket(s string, add func(int, string)) (error) {
+    lower := strings.ToLower(s)
+    for _, color := range nfColors {
+        if lower == strings.ToLower(color) {
+            sec.color = color
+            return nil
+        }
+    }
+    if strings.HasPrefix(lower, "color") {
+        n, err := strconv.Atoi(s[5:])
+        if err != nil || n < 1 || n > 56 {
+            return fmt.Errorf("incorrect color [%v]", s)
+        }
+        sec.color = "Color"+strconv.Itoa(n)
+        return nil
+    }
+    if s != "" && strings.ContainsRune("<>=", rune(s[0])) {
+        op := s[:1]
+        if len(s) > 1 && strings.ContainsRune("<>=", rune(s[1])) {
+            op = s[:2]
+        }
+        val, err := strconv.ParseFloat(strings.TrimSpace(s[len(op):]), 64)
+        if err != nil || op == "=<" || op == "=>" || op == "==" || op == "><" {
+            return fmt.Errorf("incorrect condition [%v]", s)
+        }
+        sec.cond = &nfCond{op, val}
+        return nil
+    }
+    if strings.HasPrefix(s, "$") {
+        symbol := s[1:]
+        if i := strings.Index(symbol, "-"); i != -1 {
+            symbol = symbol[:i]
+        }
+        add(nfLiteral, symbol)
+        return nil
+    }
+    if lower != "" && strings.Trim(lower, lower[:1]) == "" && strings.Contains("hms", lower[:1]) {
+        add(nfElapsed, lower)
+        return nil
+    }
+    return nil
+}
+
+//the kind of the section, minutes, subseconds, thousands and scaling.
+func (sec *nfSection) classify() {
+    sec.kind = nfTextOnly
+    hasDigit, hasGeneral := false, false
+    for i, tok := range sec.tokens {
+        switch tok.kind {
+            case nfDate, nfElapsed, nfAmPm:
+                sec.kind = nfDateTime
+            case nfDigit:
+                hasDigit = true
+            case nfGeneral:
+                hasGeneral = true
+            case nfSlash:
+                if i > 0 && sec.tokens[i-1].kind == nfDigit && sec.kind != nfDateTime {
+                    sec.kind = nfFraction
+                }
+        }
+    }
+    if sec.kind == nfDateTime {
+        sec.dateTokens()
+        return
+    }
+    if sec.kind == nfFraction {
+        sec.fractionTokens()
+        return
+    }
+    for _, tok := range sec.tokens {
+        if tok.kind == nfExp {
+            sec.kind = nfScientific
+        }
+    }
+    if sec.kind != nfScientific {
+        if hasDigit {
+            sec.kind = nfNumber
+        } else if hasGeneral {
+            sec.kind = nfGeneralNumber
+        }
+    }
+    sec.numberTokens()
+}
+
+//commas between digits are the thousands separator, commas after the last digit scale by 1000, % by 100.
+func (sec *nfSection) numberTokens() {
+    last := -1
+    for i, tok := range sec.tokens {
+        if tok.kind == nfDigit {
+            last = i
+        }
+    }
+    for i := range sec.tokens {
+        tok := &sec.tokens[i]
+        switch tok.kind {
+            case nfComma:
+                if i > last && last != -1 && sec.followsDigits(i) {
+                    sec.scale ++
+                    tok.text = ""
+                } else if sec.betweenDigits(i) && ! sec.afterPoint(i) {
+                    sec.thousands = true
+                    tok.text = ""
+                } else {
+                    tok.kind = nfLiteral
+                }
+            case nfPercent:
+                sec.percent ++
+                tok.kind = nfLiteral
+        }
+    }
+}
+
+//only commas are between the last digit and the comma at i.
+func (sec *nfSection) followsDigits(i int) (bool) {
+    for j := i - 1; j >= 0; j -- {
+        switch sec.tokens[j].kind {
+            case nfDigit:
+                return true
+            case nfComma, nfPoint:
+            default:
+                return false
+        }
+    }
+    return false
+}
+
+//
+func (sec *nfSection) betweenDigits(i int) (bool) {
+    before, after := false, false
+    for j := i - 1; j >= 0 && ! before; j -- {
+        before = sec.tokens[j].kind == nfDigit
+    }
+    for j := i + 1; j < len(sec.tokens) && ! after; j ++ {
+        after = sec.tokens[j].kind == nfDigit
+    }
+    return before && after
+}
+
+//
+func (sec *nfSection) afterPoint(i int) (bool) {
+    for j := i - 1; j >= 0; j -- {
+        if sec.tokens[j].kind == nfPoint {
+            return true
+        }
+    }
+    return false
+}
+
+//m is minutes after hours or before seconds, 0s after a point after seconds are subseconds.
+func (sec *nfSection) dateTokens() {
+    prev := -1
+    for i := range sec.tokens {
+        tok := &sec.tokens[i]
+        if tok.kind != nfDate && tok.kind != nfElapsed {
+            continue
+        }
+        if tok.kind == nfDate && tok.text[0] == 'm' && len(tok.text) <= 2 {
+            if prev != -1 && sec.tokens[prev].text[0] == 'h' || sec.nextIsSeconds(i) {
+                tok.text = strings.Repeat("n", len(tok.text))
+            }
+        }
+        prev = i
+    }
+    for i := 0; i < len(sec.tokens); i ++ {
+        tok := sec.tokens[i]
+        if (tok.kind == nfDate || tok.kind == nfElapsed) && tok.text[0] == 's' && i+2 < len(sec.tokens) && sec.tokens[i+1].kind == nfPoint && sec.tokens[i+2].kind == nfDigit {
+            j := i + 2
+            digits := ""
+            for j < len(sec.tokens) && sec.tokens[j].kind == nfDigit && len(digits) < 3 {
+                digits += "0"
+                j ++
+            }
+            sec.subsec = len(digits)
+            tokens := append([]nfToken{}, sec.tokens[:i+1]...)
+            tokens = append(tokens, nfToken{nfSubsec, digits})
+            sec.tokens = append(tokens, sec.tokens[j:]...)
+        }
+    }
+    for i := range sec.tokens {
+        switch tok := &sec.tokens[i]; tok.kind {
+            case nfDigit, nfPoint, nfComma, nfPercent, nfSlash, nfExp:
+                tok.kind = nfLiteral
+        }
+    }
+}
+
+//
+func (sec *nfSection) nextIsSeconds(i int) (bool) {
+    for j := i + 1; j < len(sec.tokens); j ++ {
+        if tok := sec.tokens[j]; tok.kind == nfDate || tok.kind == nfElapsed {
+            return tok.text[0] == 's'
+        }
+    }
+    return false
+}
+
+//Format renders val as Excel displays it, and gives the color of the section like "Red" or "Color10", "" for none.
+//numbers, time.Time, bool and string are rendered, others are rendered by String as text.
+func (nf *NumberFormat) Format(val interface{}) (text string, color string) {
+    switch one := val.(type) {
+        case nil:
+            return "", ""
+        case bool:
+            if one {
+                return "TRUE", ""
+            }
+            return "FALSE", ""
+        case string:
+            return nf.formatText(one)
+        case time.Time:
+            serial, err := ToExcelSerial(one, nf.Date1904)
+            if err != nil {
+                return "########", ""
+            }
+            return nf.formatNumber(serial)
+    }
+    if v, ok := nfFloat(val); ok {
+        return nf.formatNumber(v)
+    }
+    return nf.formatText(String(val))
+}
+
+//
+func nfFloat(val interface{}) (float64, bool) {
+    switch one := val.(type) {
+        case float64:
+            return one, true
+        case float32:
+            return float64(one), true
+    }
+    if n, ok := toInt(val); ok {
+        return float64(n), true
+    }
+    return 0, false
+}
+
+//text is rendered by the 4th section, or a section with @, or as it is.
+func (nf *NumberFormat) formatText(s string) (string, string) {
+    var sec *nfSection
+    if len(nf.sections) == 4 {
+        sec = &nf.sections[3]
+    } else {
+        for i := range nf.sections {
+            if nf.sections[i].text {
+                sec = &nf.sections[i]
+                break
+            }
+        }
+    }
+    if sec == nil {
+        return s, ""
+    }
+    var b strings.Builder
+    for _, tok := range sec.tokens {
+        switch tok.kind {
+            case nfText:
+                b.WriteString(s)
+            case nfDigit:
+            default:
+                b.WriteString(tok.text)
+        }
+    }
+    return b.String(), sec.color
+}
+
+//the section of v, and whether a minus is shown as Excel does for the first section of negative numbers.
+func (nf *NumberFormat) section(v float64) (*nfSection, bool) {
+    secs := nf.sections
+    if len(secs) == 4 {
+        secs = secs[:3]
+    }
+    if secs[0].cond != nil || len(secs) > 1 && secs[1].cond != nil {
+        switch {
+            case secs[0].cond.match(v):
+                return &secs[0], v < 0
+            case len(secs) > 1 && secs[1].cond.match(v):
+                return &secs[1], v < 0
+            case secs[0].cond != nil && len(secs) > 1 && secs[1].cond != nil && len(secs) > 2:
+                return &secs[2], v < 0
+            case len(secs) > 1 && secs[1].cond == nil:
+                return &secs[1], v < 0
+        }
+        return &secs[0], v < 0
+    }
+    switch {
+        case v < 0 && len(secs) > 1:
+            return &secs[1], false
+        case v == 0 && len(secs) > 2:
+            return &secs[2], false
+    }
+    return &secs[0], v < 0
+}
+
+//
+func (nf *NumberFormat) formatNumber(v float64) (string, string) {
+    if math.IsNaN(v) || math.IsInf(v, 0) {
+        return "#NUM!", ""
+    }
+    sec, minus := nf.section(v)
+    a := math.Abs(v)
+    var text string
+    switch sec.kind {
+        case nfDateTime:
+            if v < 0 {
+                return "########", sec.color
+            }
+            text = sec.formatDate(a, nf.Date1904)
+        case nfFraction:
+            text = sec.formatFraction(a)
+        case nfScientific:
+            text = sec.formatScientific(a)
+        case nfNumber:
+            text = sec.formatDecimal(a)
+        case nfGeneralNumber:
+            text = sec.formatLiterals(generalNumber(a))
+        default:
+            text = sec.formatLiterals("")
+    }
+    if minus {
+        text = "-"+text
+    }
+    return text, sec.color
+}
+
+//the literals of a section with General rendered as general.
+func (sec *nfSection) formatLiterals(general string) (string) {
+    var b strings.Builder
+    for _, tok := range sec.tokens {
+        switch tok.kind {
+            case nfGeneral:
+                b.WriteString(general)
+            case nfLiteral, nfComma, nfPoint, nfSlash:
+                b.WriteString(tok.text)
+        }
+    }
+    return b.String()
+}
+
+//generalNumber renders a non-negative number by General, in 11 characters at most.
+func generalNumber(a float64) (string) {
+    if a == 0 {
+        return "0"
+    }
+    e := int(math.Floor(math.Log10(a)))
+    var s string
+    switch {
+        case e >= -4 && e <= -1:
+            s = jsPrecision(a, 10+e)
+        case e >= -9 && e <= 9:
+            s = strconv.FormatFloat(a, 'f', 12, 64)
+            if s = stripDecimal(s); len(s) > 11 {
+                if s = jsPrecision(a, 10); len(stripDecimal(s)) > 11 {
+                    s = strconv.FormatFloat(a, 'e', 5, 64)
+                }
+            }
+        case e == 10:
+            s = strconv.FormatFloat(a, 'f', 0, 64)
+        default:
+            s = strconv.FormatFloat(a, 'e', 5, 64)
+    }
+    return stripDecimal(s)
+}
+
+//a number with p significant digits, in exponent notation as javascript for small and large numbers.
+func jsPrecision(a float64, p int) (string) {
+    s := strconv.FormatFloat(a, 'e', p-1, 64)
+    e, _ := strconv.Atoi(s[strings.Index(s, "e")+1:])
+    if e < -6 || e >= p {
+        return s
+    }
+    return strconv.FormatFloat(a, 'f', max(0, p-1-e), 64)
+}
+
+//trailing zeros of the decimals are removed, the exponent is like E+05.
+func stripDecimal(s string) (string) {
+    mant, exp := s, ""
+    if i := strings.IndexAny(s, "eE"); i != -1 {
+        mant, exp = s[:i], s[i+1:]
+    }
+    if strings.Contains(mant, ".") {
+        mant = strings.TrimRight(strings.TrimRight(mant, "0"), ".")
+    }
+    if exp == "" {
+        return mant
+    }
+    sign := "+"
+    if exp[0] == '-' || exp[0] == '+' {
+        sign, exp = exp[:1], exp[1:]
+    }
+    exp = strings.TrimLeft(exp, "0")
+    for len(exp) < 2 {
+        exp = "0"+exp
+    }
+    return mant+"E"+sign+exp
+}
+
+//roundDecimal rounds a non-negative number half away from zero in 15 significant digits as Excel,
+//and gives the integer digits, "0" for zero, and the decimals.
+func roundDecimal(a float64, decimals int) (string, string) {
+    s := strconv.FormatFloat(a, 'e', 14, 64)
+    i := strings.Index(s, "e")
+    e, _ := strconv.Atoi(s[i+1:])
+    digits := strings.Replace(s[:i], ".", "", 1)
+    point := e + 1
+    if point < 0 {
+        digits, point = strings.Repeat("0", -point)+digits, 0
+    }
+    cut := point + decimals
+    if cut < len(digits) {
+        up := digits[cut] >= '5'
+        digits = digits[:cut]
+        if up {
+            b := []byte(digits)
+            j := len(b) - 1
+            for ; j >= 0 && b[j] == '9'; j -- {
+                b[j] = '0'
+            }
+            if j >= 0 {
+                b[j] ++
+                digits = string(b)
+            } else {
+                digits, point = "1"+string(b), point+1
+            }
+        }
+    } else {
+        digits += strings.Repeat("0", cut-len(digits))
+    }
+    ip := strings.TrimLeft(digits[:point], "0")
+    if ip == "" {
+        ip = "0"
+    }
+    return ip, digits[point:]
+}
+
+//the indexes of the digit tokens before a point, after it, and of the point, -1 for none.
+func (sec *nfSection) digitTokens(from, to int) (ints []int, decs []int, point int) {
+    point = -1
+    for i := from; i < to; i ++ {
+        switch sec.tokens[i].kind {
+            case nfDigit:
+                if point == -1 {
+                    ints = append(ints, i)
+                } else {
+                    decs = append(decs, i)
+                }
+            case nfPoint:
+                if point == -1 {
+                    point = i
+                }
+        }
+    }
+    return
+}
+
+//the text of each digit token of the integer part, and of the decimals.
+func (sec *nfSection) fillDigits(out map[int]string, ints []int, decs []int, ip string, frac string) {
+    if ip == "0" {
+        ip = ""
+    }
+    type cell struct {
+        tok  int
+        text string
+    }
+    cells := []cell{}
+    for k := len(ints) - 1; k >= 0; k -- {
+        pos := len(ints) - 1 - k
+        text := ""
+        if di := len(ip) - 1 - pos; di >= 0 {
+            text = ip[di:di+1]
+        } else {
+            switch sec.tokens[ints[k]].text {
+                case "0":
+                    text = "0"
+                case "?":
+                    text = " "
+            }
+        }
+        cells = append(cells, cell{ints[k], text})
+    }
+    if len(ints) > 0 {
+        for di := len(ip) - len(ints) - 1; di >= 0; di -- {
+            cells = append(cells, cell{ints[0], ip[di:di+1]})
+        }
+    }
+    for k := len(cells) - 1; k >= 0; k -- {
+        text := cells[k].text
+        if sec.thousands && k > 0 && k % 3 == 0 {
+            switch {
+                case text >= "0" && text <= "9":
+                    text += ","
+                case text == " ":
+                    text += " "
+            }
+        }
+        out[cells[k].tok] += text
+    }
+    zeros := len(frac)
+    for zeros > 0 && frac[zeros-1] == '0' {
+        zeros --
+    }
+    for k, tok := range decs {
+        text := frac[k:k+1]
+        if k >= zeros {
+            switch sec.tokens[tok].text {
+                case "#":
+                    text = ""
+                case "?":
+                    text = " "
+            }
+        }
+        out[tok] = text
+    }
+}
+
+//render the tokens with the texts of digits, integer digits without tokens are before the point or at the end.
+func (sec *nfSection) render(out map[int]string, from, to int, orphan string) (string) {
+    var b strings.Builder
+    for i := from; i < to; i ++ {
+        tok := sec.tokens[i]
+        switch tok.kind {
+            case nfDigit:
+                b.WriteString(out[i])
+            case nfPoint:
+                b.WriteString(orphan)
+                orphan = ""
+                b.WriteString(".")
+            case nfLiteral, nfSlash:
+                b.WriteString(tok.text)
+        }
+    }
+    return b.String()+orphan
+}
+
+//
+func (sec *nfSection) scaled(a float64) (float64) {
+    return a * math.Pow(100, float64(sec.percent)) / math.Pow(1000, float64(sec.scale))
+}
+
+//
+func (sec *nfSection) formatDecimal(a float64) (string) {
+    ints, decs, _ := sec.digitTokens(0, len(sec.tokens))
+    ip, frac := roundDecimal(sec.scaled(a), len(decs))
+    out := map[int]string{}
+    sec.fillDigits(out, ints, decs, ip, frac)
+    orphan := ""
+    if len(ints) == 0 && ip != "0" {
+        orphan = ip
+    }
+    return sec.render(out, 0, len(sec.tokens), orphan)
+}
+
+//
+func (sec *nfSection) formatScientific(a float64) (string) {
+    expAt := 0
+    for i, tok := range sec.tokens {
+        if tok.kind == nfExp {
+            expAt = i
+            break
+        }
+    }
+    ints, decs, _ := sec.digitTokens(0, expAt)
+    width := max(1, len(ints))
+    engineering := len(ints) > 1 && sec.tokens[ints[0]].text == "#"
+    a = sec.scaled(a)
+    e := 0
+    if a != 0 {
+        e = int(math.Floor(math.Log10(a)))
+    }
+    shift := func(e int) (int) {
+        if engineering {
+            return int(math.Floor(float64(e) / float64(width))) * width
+        }
+        return e - (width - 1)
+    }
+    e = shift(e)
+    ip, frac := roundDecimal(a / math.Pow(10, float64(e)), len(decs))
+    if a != 0 && len(ip) > width {
+        e = shift(e + width)
+        if ! engineering {
+            e = shift(int(math.Floor(math.Log10(a))) + 1)
+        }
+        ip, frac = roundDecimal(a / math.Pow(10, float64(e)), len(decs))
+    }
+    out := map[int]string{}
+    sec.fillDigits(out, ints, decs, ip, frac)
+    exps := 0
+    for i := expAt + 1; i < len(sec.tokens) && sec.tokens[i].kind == nfDigit; i ++ {
+        exps ++
+    }
+    sign := ""
+    if e < 0 {
+        sign = "-"
+    } else if sec.tokens[expAt].text == "E+" {
+        sign = "+"
+    }
+    digits := strconv.Itoa(abs(e))
+    for len(digits) < exps {
+        digits = "0"+digits
+    }
+    return sec.render(out, 0, expAt, "")+"E"+sign+digits+sec.render(out, expAt+1+exps, len(sec.tokens), "")
+}
+
+//
+func abs(n int) (int) {
+    if n < 0 {
+        return -n
+    }
+    return n
+}
+
+//the numerator is the digits before the slash, the denominator is digits or a number after it.
+func (sec *nfSection) fractionTokens() {
+    for i, tok := range sec.tokens {
+        if tok.kind == nfSlash {
+            sec.slash = i
+            break
+        }
+    }
+    sec.numFrom = sec.slash
+    for sec.numFrom > 0 && sec.tokens[sec.numFrom-1].kind == nfDigit {
+        sec.numFrom --
+    }
+    sec.denTo = sec.slash + 1
+    for sec.denTo < len(sec.tokens) {
+        tok := sec.tokens[sec.denTo]
+        if tok.kind == nfDigit {
+            sec.denText += tok.text
+            sec.denTo ++
+            continue
+        }
+        n := len(tok.text) - len(strings.TrimLeft(tok.text, "0123456789"))
+        if tok.kind != nfLiteral || n == 0 {
+            break
+        }
+        sec.denText += tok.text[:n]
+        sec.tokens[sec.denTo].text = tok.text[n:]
+        if n < len(tok.text) {
+            break
+        }
+        sec.denTo ++
+    }
+    for i := range sec.tokens {
+        if tok := &sec.tokens[i]; tok.kind == nfComma || tok.kind == nfPercent {
+            if tok.kind == nfPercent {
+                sec.percent ++
+            }
+            tok.kind = nfLiteral
+        }
+    }
+}
+
+//
+func (sec *nfSection) formatFraction(a float64) (string) {
+    ints, _, _ := sec.digitTokens(0, sec.numFrom)
+    a = sec.scaled(a)
+    whole := 0.0
+    if len(ints) > 0 {
+        whole = math.Floor(a)
+        a -= whole
+    }
+    fixed := strings.Trim(sec.denText, "0123456789") == "" && strings.Trim(sec.denText, "0") != ""
+    var num, den int64
+    if fixed {
+        den, _ = strconv.ParseInt(sec.denText, 10, 64)
+        num = int64(math.Round(a * float64(den)))
+    } else {
+        num, den = approxFraction(a, int64(math.Pow(10, float64(len(sec.denText))))-1)
+    }
+    if len(ints) > 0 && num == den {
+        whole, num = whole+1, 0
+    }
+    out := map[int]string{}
+    sec.fillDigits(out, ints, nil, strconv.FormatFloat(whole, 'f', 0, 64), "")
+    if whole == 0 && num == 0 && len(ints) > 0 {
+        out[ints[len(ints)-1]] = "0"
+    }
+    head := sec.render(out, 0, sec.numFrom, "")
+    tail := sec.render(out, sec.denTo, len(sec.tokens), "")
+    if num == 0 && len(ints) > 0 {
+        return head+strings.Repeat(" ", sec.slash-sec.numFrom+1+len(sec.denText))+tail
+    }
+    ns := strconv.FormatInt(num, 10)
+    for k := sec.slash - sec.numFrom - len(ns) - 1; k >= 0; k -- {
+        switch sec.tokens[sec.numFrom+k].text {
+            case "?":
+                ns = " "+ns
+            case "0":
+                ns = "0"+ns
+        }
+    }
+    ds := sec.denText
+    if ! fixed {
+        ds = strconv.FormatInt(den, 10)
+        for k := len(ds); k < len(sec.denText); k ++ {
+            switch sec.denText[k] {
+                case '?':
+                    ds += " "
+                case '0':
+                    ds = "0"+ds
+            }
+        }
+    }
+    return head+ns+"/"+ds+tail
+}
+
+//the closest fraction of 0 <= f < 1 whose denominator is at most maxDen.
+func approxFraction(f float64, maxDen int64) (int64, int64) {
+    if maxDen < 1 {
+        maxDen = 1
+    }
+    p0, q0, p1, q1 := int64(0), int64(1), int64(1), int64(0)
+    x := f
+    for {
+        a := int64(math.Floor(x))
+        p2, q2 := a*p1+p0, a*q1+q0
+        if q2 > maxDen {
+            k := (maxDen - q0) / q1
+            pk, qk := k*p1+p0, k*q1+q0
+            if math.Abs(f - float64(pk)/float64(qk)) < math.Abs(f - float64(p1)/float64(q1)) {
+                return pk, qk
+            }
+            return p1, q1
+        }
+        p0, q0, p1, q1 = p1, q1, p2, q2
+        if x - float64(a) < 1e-12 || math.Abs(f - float64(p1)/float64(q1)) < 1e-15 {
+            return p1, q1
+        }
+        x = 1 / (x - float64(a))
+    }
+}
+
+//names of months and days of Excel in English.
+var (
+    nfMonths = []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+    nfDays   = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+)
+
+//the date of a day serial, with 1900-01-00 and the nonexistent 1900-02-29 of the 1900 date system, and its weekday.
+func serialDate(day int64, date1904 bool) (year int, month int, mday int, weekday int) {
+    if date1904 {
+        t := oaEpoch.AddDate(0, 0, int(day)+1462)
+        return t.Year(), int(t.Month()), t.Day(), int(t.Weekday())
+    }
+    weekday = int((day + 6) % 7)
+    switch {
+        case day == 0:
+            return 1900, 1, 0, weekday
+        case day == 60:
+            return 1900, 2, 29, weekday
+        case day < 60:
+            day ++
+    }
+    t := oaEpoch.AddDate(0, 0, int(day))
+    return t.Year(), int(t.Month()), t.Day(), weekday
+}
+
+//
+func (sec *nfSection) formatDate(serial float64, date1904 bool) (string) {
+    unit := math.Pow(10, float64(sec.subsec))
+    units := int64(math.Round(serial * 86400 * unit))
+    perDay := int64(86400 * unit)
+    day, rem := units / perDay, units % perDay
+    secs := rem / int64(unit)
+    sub := rem % int64(unit)
+    hour, minute, second := secs / 3600, secs / 60 % 60, secs % 60
+    year, month, mday, weekday := serialDate(day, date1904)
+    totalSecs := units / int64(unit)
+    pad := func(n int64, width int) (string) {
+        s := strconv.FormatInt(n, 10)
+        for len(s) < width {
+            s = "0"+s
+        }
+        return s
+    }
+    var b strings.Builder
+    for _, tok := range sec.tokens {
+        n := len(tok.text)
+        switch tok.kind {
+            case nfDate:
+                switch tok.text[0] {
+                    case 'y':
+                        if n <= 2 {
+                            b.WriteString(pad(int64(year % 100), 2))
+                        } else {
+                            b.WriteString(pad(int64(year), 4))
+                        }
+                    case 'm':
+                        switch {
+                            case n == 1, n == 2:
+                                b.WriteString(pad(int64(month), n))
+                            case n == 3:
+                                b.WriteString(nfMonths[month-1][:3])
+                            case n == 4:
+                                b.WriteString(nfMonths[month-1])
+                            default:
+                                b.WriteString(nfMonths[month-1][:1])
+                        }
+                    case 'd':
+                        switch {
+                            case n <= 2:
+                                b.WriteString(pad(int64(mday), n))
+                            case n == 3:
+                                b.WriteString(nfDays[weekday][:3])
+                            default:
+                                b.WriteString(nfDays[weekday])
+                        }
+                    case 'h':
+                        h := hour
+                        if sec.ampm {
+                            if h = h % 12; h == 0 {
+                                h = 12
+                            }
+                        }
+                        b.WriteString(pad(h, min(n, 2)))
+                    case 'n':
+                        b.WriteString(pad(minute, min(n, 2)))
+                    case 's':
+                        b.WriteString(pad(second, min(n, 2)))
+                }
+            case nfElapsed:
+                switch tok.text[0] {
+                    case 'h':
+                        b.WriteString(pad(totalSecs / 3600, n))
+                        hour = 0
+                    case 'm':
+                        b.WriteString(pad(totalSecs / 60, n))
+                    case 's':
+                        b.WriteString(pad(totalSecs, n))
+                }
+            case nfSubsec:
+                b.WriteString("."+pad(sub, sec.subsec))
+            case nfAmPm:
+                am := hour < 12
+                switch {
+                    case n == 5 && am:
+                        b.WriteString(tok.text[:2])
+                    case n == 5:
+                        b.WriteString(string(tok.text[3])+string(tok.text[4]))
+                    case am:
+                        b.WriteString(tok.text[:1])
+                    default:
+                        b.WriteString(tok.text[2:3])
+                }
+            case nfLiteral:
+                b.WriteString(tok.text)
+        }
+    }
+    return b.String()
+}
@@ -0,0 +1,119 @@
+package excel
+
+import (
+    "errors"
+    "testing"
+    "time"
+)
+
+//the texts are as displayed by Excel for the values in cells of the formats.
+func TestFormatNumber(t *testing.T) {
+    tests := []struct {
+        val  interface{}
+        code string
+        want string
+    }{
+        {45123.5, "yyyy-mm-dd hh:mm", "2023-07-16 12:00"},
+        {1234.5, "$#,##0.00", "$1,234.50"},
+        {-1234.5, "$#,##0.00", "-$1,234.50"},
+        {-1234.5, "#,##0.00;(#,##0.00)", "(1,234.50)"},
+        {0, "0.00;-0.00;\"zero\"", "zero"},
+        {"abc", "0;0;0;\"t:\"@", "t:abc"},
+        {"abc", "0.00", "abc"},
+        {1234567.891, "#,##0", "1,234,568"},
+        {1234567.891, "#,##0,", "1,235"},
+        {1234567.891, "0.0,,\"M\"", "1.2M"},
+        {0.256, "0.0%", "25.6%"},
+        {12345.678, "0.00E+00", "1.23E+04"},
+        {0.000123, "0.00E+00", "1.23E-04"},
+        {12345.678, "##0.0E+0", "12.3E+3"},
+        {1.5, "# ?/?", "1 1/2"},
+        {0.3333, "# ??/??", "  1/3 "},
+        {2.25, "?/?", "9/4"},
+        {1.3, "# ?/8", "1 2/8"},
+        {3, "# ?/?", "3    "},
+        {1.5, "[h]:mm:ss", "36:00:00"},
+        {0.5, "h:mm AM/PM", "12:00 PM"},
+        {0.25, "h:mm a/p", "6:00 a"},
+        {0.5 + 1.5/86400, "hh:mm:ss.00", "12:00:01.50"},
+        {45123, "dddd, mmmm d, yyyy", "Sunday, July 16, 2023"},
+        {45123, "ddd mmm yy", "Sun Jul 23"},
+        {60, "yyyy-mm-dd", "1900-02-29"},
+        {0, "yyyy-mm-dd", "1900-01-00"},
+        {1, "dddd", "Sunday"},
+        {-1, "yyyy-mm-dd", "########"},
+        {150, "[<100]0;[>=100]\"big\"0", "big150"},
+        {50, "[<100]0;[>=100]\"big\"0", "50"},
+        {123456789012.0, "General", "1.23457E+11"},
+        {1.0 / 3, "General", "0.333333333"},
+        {1234.5, "General", "1234.5"},
+        {-2, "", "-2"},
+        {0.00001, "General", "0.00001"},
+        {5, "0.##", "5."},
+        {0.5, "#", "1"},
+        {0.5, ".00", ".50"},
+        {12.5, ".00", "12.50"},
+        {-0.4, "0", "-0"},
+        {7, "000", "007"},
+        {7, "??0", "  7"},
+        {true, "0", "TRUE"},
+        {time.Date(2023, 7, 16, 12, 0, 0, 0, DateLocation), "yyyy-mm-dd hh:mm", "2023-07-16 12:00"},
+        {1234.5, "[$€-407] #,##0.00", "€ 1,234.50"},
+        {3.5, "0_);(0)", "4 "},
+        {1.005, "0.00", "1.01"},
+        {0.5, "mm:ss", "00:00"},
+        {1.5 / 24, "h \"h\" m \"m\"", "1 h 30 m"},
+        {"hello", "@", "hello"},
+        {0.5, "0%", "50%"},
+        {45123, "d-mmm", "16-Jul"},
+        {45123, "mm/dd/yyyy", "07/16/2023"},
+        {45123, "mmmmm", "J"},
+        {0.75, "h:mm:ss AM/PM", "6:00:00 PM"},
+        {1.5 / 24, "[mm]:ss", "90:00"},
+        {-12345.678, "0.0E+00", "-1.2E+04"},
+        {1000000, "#,##0.00", "1,000,000.00"},
+        {1234.5678, "#,##0.000", "1,234.568"},
+    }
+    for _, test := range tests {
+        got, err := FormatNumber(test.val, test.code)
+        if err != nil || got != test.want {
+            t.Errorf("FormatNumber(%v, %q) = %q, %v, want %q", test.val, test.code, got, err, test.want)
+        }
+    }
+}
+
+func TestNumberFormatColor(t *testing.T) {
+    nf, err := ParseNumberFormat("[Blue]0;[Red]-0;[Color10]0")
+    if err != nil {
+        t.Fatal(err)
+    }
+    tests := []struct {
+        val   float64
+        text  string
+        color string
+    }{{1, "1", "Blue"}, {-1, "-1", "Red"}, {0, "0", "Color10"}}
+    for _, test := range tests {
+        if text, color := nf.Format(test.val); text != test.text || color != test.color {
+            t.Errorf("Format(%v) = %q, %q, want %q, %q", test.val, text, color, test.text, test.color)
+        }
+    }
+}
+
+func TestNumberFormatDate1904(t *testing.T) {
+    nf, err := ParseNumberFormat("yyyy-mm-dd")
+    if err != nil {
+        t.Fatal(err)
+    }
+    nf.Date1904 = true
+    if text, _ := nf.Format(0); text != "1904-01-01" {
+        t.Fatalf("day 0 of 1904 is %q", text)
+    }
+}
+
+func TestNumberFormatErrors(t *testing.T) {
+    for _, code := range []string{"0;0;0;@;0", "\"abc", "[Red", "[Color99]0", "[<x]0"} {
+        if _, err := ParseNumberFormat(code); ! errors.Is(err, ErrNumberFormat) {
+            t.Errorf("ParseNumberFormat(%q): %v", code, err)
+        }
+    }
+}
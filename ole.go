@@ -0,0 +1,172 @@
+package excel
+
+import (
+    "math"
+    "time"
+    "unsafe"
+    "reflect"
+    "github.com/go-ole/go-ole"
+    "github.com/go-ole/go-ole/oleutil"
+)
+
+//OleDispatch is the go-ole backend of Dispatcher.
+type OleDispatch struct {
+    *ole.IDispatch
+}
+
+//the Excel.Application of go-ole backend, which uninitializes COM on Release.
+type oleApplication struct {
+    OleDispatch
+    unknown *ole.IUnknown
+}
+
+func init() {
+    RegisterBackend("ole", NewOleApplication)
+}
+
+//NewOleApplication creates Excel.Application through COM.
+func NewOleApplication() (Dispatcher, error) {
+    ole.CoInitialize(0)
+    app, err := oleutil.CreateObject("Excel.Application")
+    if err != nil {
+        ole.CoUninitialize()
+        return nil, err
+    }
+    excel, err := app.QueryInterface(ole.IID_IDispatch)
+    if err != nil {
+        app.Release()
+        ole.CoUninitialize()
+        return nil, err
+    }
+    return oleApplication{OleDispatch{excel}, app}, nil
+}
+
+//
+func (app oleApplication) Release() (n int32) {
+    n = app.OleDispatch.Release()
+    app.unknown.Release()
+    ole.CoUninitialize()
+    return
+}
+
+//
+func (disp OleDispatch) GetProperty(name string, params... interface{}) (interface{}, error) {
+    ps, release, err := oleParams(params)
+    defer release()
+    if err != nil {
+        return nil, err
+    }
+    ret, err := oleutil.GetProperty(disp.IDispatch, name, ps...)
+    return oleValue(ret), err
+}
+
+//
+func (disp OleDispatch) PutProperty(name string, params... interface{}) (error) {
+    ps, release, err := oleParams(params)
+    defer release()
+    if err != nil {
+        return err
+    }
+    _, err = oleutil.PutProperty(disp.IDispatch, name, ps...)
+    return err
+}
+
+//
+func (disp OleDispatch) CallMethod(name string, params... interface{}) (interface{}, error) {
+    ps, release, err := oleParams(params)
+    defer release()
+    if err != nil {
+        return nil, err
+    }
+    ret, err := oleutil.CallMethod(disp.IDispatch, name, ps...)
+    return oleValue(ret), err
+}
+
+//
+func oleDate(t time.Time) (*ole.VARIANT) {
+    va := ole.NewVariant(ole.VT_DATE, int64(math.Float64bits(ToOADate(t))))
+    return &va
+}
+
+//
+func oleValue(va *ole.VARIANT) (interface{}) {
+    if va == nil {
+        return nil
+    } else if va.VT == ole.VT_DISPATCH {
+        return OleDispatch{va.ToIDispatch()}
+    }
+    return VARIANT{va}.Value()
+}
+
+//Dispatcher params are passed to COM as *ole.IDispatch, time.Time as VT_DATE, omitted ones as DISP_E_PARAMNOTFOUND,
+//2-D slices like [][]interface{}, [][]string or [][]float64 as 2-D SAFEARRAY.
+//release frees the SAFEARRAYs after the call.
+func oleParams(params []interface{}) (ret []interface{}, release func(), err error) {
+    arrays := []*ole.SafeArray{}
+    release = func() {
+        for _, array := range arrays {
+            (&ole.SafeArrayConversion{Array: array}).Release()
+        }
+    }
+    ret = make([]interface{}, len(params))
+    for i, param := range params {
+        ret[i] = param
+        if t, ok := param.(time.Time); ok {
+            ret[i] = oleDate(t)
+        } else if secret, ok := param.(secretArg); ok {
+            ret[i] = string(secret)
+        } else if _, ok := param.(missingArg); ok {
+            va := ole.NewVariant(ole.VT_ERROR, hrParamNotFound)
+            ret[i] = &va
+        } else if is2D(param) {
+            array, e := safeArrayFrom(param)
+            if e != nil {
+                return nil, release, e
+            }
+            arrays = append(arrays, array)
+            va := ole.NewVariant(ole.VT_ARRAY|ole.VT_VARIANT, int64(uintptr(unsafe.Pointer(array))))
+            ret[i] = &va
+        } else if disp, ok := param.(Dispatcher); ok {
+            switch one := unwrap(disp).(type) {
+                case OleDispatch:
+                    ret[i] = one.IDispatch
+                case oleApplication:
+                    ret[i] = one.IDispatch
+            }
+        }
+    }
+    return
+}
+
+//a VARIANT of the value, strings are BSTR which is freed by ole.VariantClear.
+func variantOf(val interface{}) (ole.VARIANT) {
+    switch v := val.(type) {
+        case nil:
+            return ole.NewVariant(ole.VT_EMPTY, 0)
+        case string:
+            return ole.NewVariant(ole.VT_BSTR, int64(uintptr(unsafe.Pointer(ole.SysAllocString(v)))))
+        case bool:
+            if v {
+                return ole.NewVariant(ole.VT_BOOL, 0xffff)
+            }
+            return ole.NewVariant(ole.VT_BOOL, 0)
+        case time.Time:
+            return ole.NewVariant(ole.VT_DATE, int64(math.Float64bits(ToOADate(v))))
+    }
+    rv := reflect.ValueOf(val)
+    switch rv.Kind() {
+        case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+            if n := rv.Int(); n >= math.MinInt32 && n <= math.MaxInt32 {
+                return ole.NewVariant(ole.VT_I4, n)
+            }
+            return ole.NewVariant(ole.VT_R8, int64(math.Float64bits(float64(rv.Int()))))
+        case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+            if n := rv.Uint(); n <= math.MaxInt32 {
+                return ole.NewVariant(ole.VT_I4, int64(n))
+            }
+            return ole.NewVariant(ole.VT_R8, int64(math.Float64bits(float64(rv.Uint()))))
+        case reflect.Float32, reflect.Float64:
+            return ole.NewVariant(ole.VT_R8, int64(math.Float64bits(rv.Float())))
+    }
+    return ole.NewVariant(ole.VT_BSTR, int64(uintptr(unsafe.Pointer(ole.SysAllocString(String(val))))))
+}
@@ -0,0 +1,42 @@
+package excel
+
+import (
+    "math"
+    "testing"
+    "time"
+    "github.com/go-ole/go-ole"
+)
+
+func TestVariantOf(t *testing.T) {
+    day := time.Date(2023, 7, 16, 12, 0, 0, 0, time.Local)
+    type text string
+    tests := []struct {
+        val  interface{}
+        vt   ole.VT
+        bits int64
+    }{
+        {nil, ole.VT_EMPTY, 0},
+        {true, ole.VT_BOOL, 0xffff},
+        {false, ole.VT_BOOL, 0},
+        {int(5), ole.VT_I4, 5},
+        {int8(-5), ole.VT_I4, -5},
+        {int64(math.MaxInt32), ole.VT_I4, math.MaxInt32},
+        {int64(1) << 40, ole.VT_R8, int64(math.Float64bits(1 << 40))},
+        {int64(math.MinInt32) - 1, ole.VT_R8, int64(math.Float64bits(math.MinInt32 - 1))},
+        {uint16(7), ole.VT_I4, 7},
+        {uint64(math.MaxInt32) + 1, ole.VT_R8, int64(math.Float64bits(math.MaxInt32 + 1))},
+        {float32(1.5), ole.VT_R8, int64(math.Float64bits(1.5))},
+        {2.25, ole.VT_R8, int64(math.Float64bits(2.25))},
+        {day, ole.VT_DATE, int64(math.Float64bits(ToOADate(day)))},
+    }
+    for _, test := range tests {
+        if v := variantOf(test.val); v.VT != test.vt || v.Val != test.bits {
+            t.Errorf("variantOf(%#v) = %v %#x, want %v %#x", test.val, v.VT, v.Val, test.vt, test.bits)
+        }
+    }
+    for _, val := range []interface{}{"a", text("b"), []int{1}} {
+        if v := variantOf(val); v.VT != ole.VT_BSTR {
+            t.Errorf("variantOf(%#v) is %v, want VT_BSTR", val, v.VT)
+        }
+    }
+}
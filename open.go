@@ -0,0 +1,105 @@
+package excel
+
+import (
+    "fmt"
+)
+
+//values of OpenOptions.UpdateLinks.
+const (
+    UpdateLinksDefault = iota   //as the workbook says
+    UpdateLinksNever            //external references are not updated
+    UpdateLinksAlways           //external references are updated
+)
+
+//values of OpenOptions.CorruptLoad.
+const (
+    CorruptLoadDefault = iota   //normal load
+    CorruptLoadRepair           //xlRepairFile
+    CorruptLoadExtractData      //xlExtractData
+)
+
+//OpenOptions are the optional arguments of Workbooks.Open, zero values are omitted.
+type OpenOptions struct {
+    ReadOnly         bool
+    Password         string     //password to open the workbook
+    WriteResPassword string     //password to write the workbook
+    UpdateLinks      int        //UpdateLinksDefault, UpdateLinksNever or UpdateLinksAlways
+    Delimiter        string     //delimiter of text files, Format is 6 if it is set
+    Format           int        //delimiter of text files, 1 tabs, 2 commas, 3 spaces, 4 semicolons, 5 nothing, 6 Delimiter
+    Local            bool       //text files are in the language of Excel instead of VBA
+    CorruptLoad      int        //CorruptLoadDefault, CorruptLoadRepair or CorruptLoadExtractData
+}
+
+//the arguments of Workbooks.Open by position:
+//FileName, UpdateLinks, ReadOnly, Format, Password, WriteResPassword, IgnoreReadOnlyRecommended,
+//Origin, Delimiter, Editable, Notify, Converter, AddToMru, Local, CorruptLoad.
+func (opts OpenOptions) args(full string) ([]interface{}, error) {
+    args := make([]interface{}, 15)
+    args[0] = full
+    switch opts.UpdateLinks {
+        case UpdateLinksDefault:
+        case UpdateLinksNever:
+            args[1] = 0
+        case UpdateLinksAlways:
+            args[1] = 3
+        default:
+            return nil, fmt.Errorf("incorrect UpdateLinks %v", opts.UpdateLinks)
+    }
+    if opts.ReadOnly {
+        args[2] = true
+    }
+    format := opts.Format
+    if format == 0 && opts.Delimiter != "" {
+        format = 6
+    }
+    if format < 0 || format > 6 {
+        return nil, fmt.Errorf("incorrect Format %v, want 1 to 6", format)
+    } else if format == 6 && len([]rune(opts.Delimiter)) != 1 {
+        return nil, fmt.Errorf("incorrect Delimiter %q, want one character", opts.Delimiter)
+    } else if format > 0 {
+        args[3] = format
+    }
+    if opts.Password != "" {
+        args[4] = secretArg(opts.Password)
+    }
+    if opts.WriteResPassword != "" {
+        args[5] = secretArg(opts.WriteResPassword)
+    }
+    if opts.Delimiter != "" {
+        args[8] = opts.Delimiter
+    }
+    if opts.Local {
+        args[13] = true
+    }
+    switch opts.CorruptLoad {
+        case CorruptLoadDefault:
+        case CorruptLoadRepair, CorruptLoadExtractData:
+            args[14] = opts.CorruptLoad
+        default:
+            return nil, fmt.Errorf("incorrect CorruptLoad %v", opts.CorruptLoad)
+    }
+    return omitArgs(args), nil
+}
+
+//trailing nil arguments are dropped, others are omitted by missingArg, the first is kept.
+func omitArgs(args []interface{}) ([]interface{}) {
+    n := len(args)
+    for n > 1 && args[n-1] == nil {
+        n --
+    }
+    args = args[:n]
+    for i, arg := range args {
+        if arg == nil {
+            args[i] = missingArg{}
+        }
+    }
+    return args
+}
+
+//the OpenOptions of Option{"Open": OpenOptions{...}}.
+func (opt Option) openOptions() ([]OpenOptions) {
+    if opts, ok := opt["Open"].(OpenOptions); ok {
+        return []OpenOptions{opts}
+    }
+    return nil
+}
@@ -0,0 +1,63 @@
+package excel
+
+import (
+    "reflect"
+    "testing"
+)
+
+func TestOpenOptionsArgs(t *testing.T) {
+    m := missingArg{}
+    tests := []struct {
+        name string
+        opts OpenOptions
+        want []interface{}
+    }{
+        {"none", OpenOptions{}, []interface{}{"a.xlsx"}},
+        {"UpdateLinksNever", OpenOptions{UpdateLinks: UpdateLinksNever}, []interface{}{"a.xlsx", 0}},
+        {"UpdateLinksAlways", OpenOptions{UpdateLinks: UpdateLinksAlways}, []interface{}{"a.xlsx", 3}},
+        {"ReadOnly", OpenOptions{ReadOnly: true}, []interface{}{"a.xlsx", m, true}},
+        {"Format", OpenOptions{Format: 4}, []interface{}{"a.xlsx", m, m, 4}},
+        {"Password", OpenOptions{Password: "p"}, []interface{}{"a.xlsx", m, m, m, secretArg("p")}},
+        {"WriteResPassword", OpenOptions{WriteResPassword: "w"}, []interface{}{"a.xlsx", m, m, m, m, secretArg("w")}},
+        {"Delimiter", OpenOptions{Delimiter: "|"}, []interface{}{"a.xlsx", m, m, 6, m, m, m, m, "|"}},
+        {"Local", OpenOptions{Local: true}, []interface{}{"a.xlsx", m, m, m, m, m, m, m, m, m, m, m, m, true}},
+        {"CorruptLoadRepair", OpenOptions{CorruptLoad: CorruptLoadRepair}, []interface{}{"a.xlsx", m, m, m, m, m, m, m, m, m, m, m, m, m, CorruptLoadRepair}},
+        {"CorruptLoadExtractData", OpenOptions{CorruptLoad: CorruptLoadExtractData}, []interface{}{"a.xlsx", m, m, m, m, m, m, m, m, m, m, m, m, m, CorruptLoadExtractData}},
+        {"all", OpenOptions{ReadOnly: true, Password: "p", WriteResPassword: "w", UpdateLinks: UpdateLinksNever, Delimiter: ";", Local: true, CorruptLoad: CorruptLoadRepair},
+            []interface{}{"a.xlsx", 0, true, 6, secretArg("p"), secretArg("w"), m, m, ";", m, m, m, m, true, CorruptLoadRepair}},
+    }
+    for _, test := range tests {
+        args, err := test.opts.args("a.xlsx")
+        if err != nil || ! reflect.DeepEqual(args, test.want) {
+            t.Errorf("%v: args are %#v, %v, want %#v", test.name, args, err, test.want)
+        }
+    }
+    for _, opts := range []OpenOptions{{UpdateLinks: 3}, {Format: 7}, {Format: -1}, {Format: 6}, {Delimiter: ";;"}, {CorruptLoad: 3}} {
+        if args, err := opts.args("a.xlsx"); err == nil {
+            t.Errorf("%+v is accepted: %#v", opts, args)
+        }
+    }
+}
+
+func TestOpenOptionsOfOpen(t *testing.T) {
+    app := NewFakeExcel()
+    wbs, _ := app.GetProperty("Workbooks")
+    var got []interface{}
+    open := wbs.(*FakeDispatch).Methods["open"]
+    wbs.(*FakeDispatch).OnCall("Open", func(args... interface{}) (interface{}, error) {
+        got = args
+        return open(args...)
+    })
+    mso, err := Open("a.csv", Option{"Backend": app, "Open": OpenOptions{ReadOnly: true, Delimiter: ";"}})
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer mso.Quit()
+    want := []interface{}{"a.csv", missingArg{}, true, 6, missingArg{}, missingArg{}, missingArg{}, missingArg{}, ";"}
+    if ! reflect.DeepEqual(got, want) {
+        t.Fatalf("Workbooks.Open gets %#v, want %#v", got, want)
+    }
+    if _, err = mso.OpenWorkBook("b.xlsx", OpenOptions{Format: 9}); err == nil {
+        t.Fatal("an incorrect Format is passed to Workbooks.Open")
+    }
+}
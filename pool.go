@@ -0,0 +1,304 @@
+package excel
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "sync"
+)
+
+//ErrPoolClosed is returned by Borrow after Pool.Close.
+var ErrPoolClosed = errors.New("excel pool closed")
+
+//PoolOptions configures a Pool.
+type PoolOptions struct {
+    Min          int                         //instances started by NewPool and kept after recycling, Max at most
+    Max          int                         //instances at most, Borrow waits when all are borrowed, 0 for no limit
+    Option       Option                      //Option of the instances, which is reset on Return
+    MaxWorkBooks int                         //recycle an instance after this many workbooks are returned with it, 0 for no limit
+    New          func(Option) (*MSO, error)  //nil to start Excel by Initialize
+    Check        func(*MSO) error            //health check on Borrow, nil to get Version
+}
+
+//PoolStats counts the instances of a Pool.
+type PoolStats struct {
+    Idle     int
+    Borrowed int
+    Created  int
+    Recycled int
+    Failed   int    //starts which failed, in NewPool, Borrow or refilling Min after recycling
+}
+
+//Pool keeps Excel instances for reuse, as starting Excel takes seconds.
+type Pool struct {
+    opts     PoolOptions
+    mutex    sync.Mutex
+    idle     []*MSO
+    borrowed map[*MSO]bool
+    uses     map[*MSO]int
+    slots    chan struct{}
+    starting int
+    stats    PoolStats
+    closed   bool
+    refills  sync.WaitGroup
+}
+
+//NewPool starts opts.Min instances.
+func NewPool(opts PoolOptions) (pool *Pool, err error) {
+    if opts.Max > 0 && opts.Min > opts.Max {
+        return nil, fmt.Errorf("excel pool: Min %v is more than Max %v", opts.Min, opts.Max)
+    }
+    if opts.New == nil {
+        opts.New = initialize
+    }
+    if opts.Check == nil {
+        opts.Check = checkVersion
+    }
+    pool = &Pool{opts: opts, borrowed: map[*MSO]bool{}, uses: map[*MSO]int{}}
+    if opts.Max > 0 {
+        pool.slots = make(chan struct{}, opts.Max)
+    }
+    for i := 0; i < opts.Min; i ++ {
+        mso, e := pool.start()
+        if e != nil {
+            pool.Close()
+            return nil, e
+        }
+        pool.idle = append(pool.idle, mso)
+    }
+    return
+}
+
+//Initialize without panic.
+func initialize(opt Option) (mso *MSO, err error) {
+    defer Except("Initialize", &err)
+    mso = Initialize(opt)
+    return
+}
+
+//
+func checkVersion(mso *MSO) (error) {
+    _, err := mso.IdExcel.GetProperty("Version")
+    return err
+}
+
+//start an instance with its own copy of Option.
+func (pool *Pool) start() (*MSO, error) {
+    opt := Option{}
+    for key, val := range pool.opts.Option {
+        opt[key] = val
+    }
+    mso, err := pool.opts.New(opt)
+    pool.mutex.Lock()
+    defer pool.mutex.Unlock()
+    if err != nil {
+        pool.stats.Failed ++
+        return nil, err
+    }
+    pool.stats.Created ++
+    return mso, nil
+}
+
+//Borrow gets an idle instance which passes the health check, or starts one, it waits if Max instances are borrowed.
+//the check and the start are done in ctx too, an instance timed out is recycled when they return,
+//after Option "KillOnTimeout" kills it, and its place in Max is kept till then.
+func (pool *Pool) Borrow(ctx context.Context) (mso *MSO, err error) {
+    if pool.slots != nil {
+        select {
+            case pool.slots <- struct{}{}:
+            case <-ctx.Done():
+                return nil, timeoutError("Pool.Borrow", ctx.Err())
+        }
+    }
+    abandoned := false
+    defer func() {
+        if err != nil && ! abandoned {
+            pool.release()
+        }
+    }()
+    for {
+        pool.mutex.Lock()
+        if pool.closed {
+            pool.mutex.Unlock()
+            return nil, ErrPoolClosed
+        }
+        var one *MSO
+        if n := len(pool.idle); n > 0 {
+            one, pool.idle = pool.idle[n-1], pool.idle[:n-1]
+        } else {
+            pool.starting ++
+        }
+        pool.mutex.Unlock()
+        mso, abandoned, err = pool.prepare(ctx, one)
+        if err == nil || one == nil || abandoned || errors.Is(err, ErrTimeout) {
+            return
+        }
+    }
+}
+
+//check the idle instance one, or start one if it is nil, in ctx, the instance is borrowed if it is ok.
+//on timeout it is abandoned to a goroutine which recycles it and frees the place of Borrow.
+func (pool *Pool) prepare(ctx context.Context, one *MSO) (mso *MSO, abandoned bool, err error) {
+    got := make(chan *MSO, 1)
+    err = runContext(ctx, "Pool.Borrow", func() (err error) {
+        mso := one
+        if mso == nil {
+            mso, err = pool.start()
+        } else {
+            err = pool.opts.Check(mso)
+        }
+        got <- mso
+        return
+    }, func() {
+        abandoned = true
+        if one != nil {
+            one.onTimeout()
+        }
+        go func() {
+            pool.done(<-got, one == nil, false)
+            pool.release()
+        }()
+    })
+    if abandoned {
+        return nil, true, err
+    }
+    select {
+        case mso = <-got:
+        default:
+            mso = one
+    }
+    if ! pool.done(mso, one == nil, err == nil) {
+        mso = nil
+    }
+    return
+}
+
+//end the start or the check of mso, it is borrowed if ok, or else recycled. false if it is not borrowed.
+func (pool *Pool) done(mso *MSO, started bool, ok bool) (bool) {
+    pool.mutex.Lock()
+    if started {
+        pool.starting --
+    }
+    if ok {
+        pool.borrowed[mso] = true
+    }
+    pool.mutex.Unlock()
+    if ! ok && mso != nil {
+        pool.recycle(mso)
+    }
+    return ok
+}
+
+//free the place of an instance in Max.
+func (pool *Pool) release() {
+    if pool.slots != nil {
+        <-pool.slots
+    }
+}
+
+//Return gives back a borrowed instance, its workbooks are closed without saving and its Option is reset.
+//it is recycled if err is not nil, e.g. the error of the work with it, or it has been used for MaxWorkBooks.
+func (pool *Pool) Return(mso *MSO, err error) {
+    pool.mutex.Lock()
+    if _, ok := pool.borrowed[mso]; ! ok {
+        pool.mutex.Unlock()
+        return
+    }
+    delete(pool.borrowed, mso)
+    closed := pool.closed
+    pool.mutex.Unlock()
+    defer pool.release()
+
+    if err == nil && ! closed {
+        err = pool.reset(mso)
+    }
+    pool.mutex.Lock()
+    if err == nil && ! pool.closed && (pool.opts.MaxWorkBooks <= 0 || pool.uses[mso] < pool.opts.MaxWorkBooks) {
+        pool.idle = append(pool.idle, mso)
+        pool.mutex.Unlock()
+        return
+    }
+    pool.mutex.Unlock()
+    pool.recycle(mso)
+}
+
+//close the workbooks, remove the temp files and reset Option.
+func (pool *Pool) reset(mso *MSO) (err error) {
+    defer Except("Pool.reset", &err)
+    wbs := mso.WorkBooks()
+    for _, wb := range wbs {
+        mustCall(wb.Dispatcher, "Close", false)
+        wb.Release()
+    }
+    mso.WorkBook = WorkBook{}
+    mso.removeTemps()
+    pool.mutex.Lock()
+    pool.uses[mso] += len(wbs)
+    pool.mutex.Unlock()
+    for key, val := range pool.opts.Option {
+        if err = mso.SetOption(key, val); err != nil {
+            return
+        }
+    }
+    return
+}
+
+//quit the instance and start another one if fewer than Min are left.
+func (pool *Pool) recycle(mso *MSO) {
+    mso.Quit()
+    pool.mutex.Lock()
+    defer pool.mutex.Unlock()
+    delete(pool.uses, mso)
+    pool.stats.Recycled ++
+    if ! pool.closed && pool.live() < pool.opts.Min {
+        pool.starting ++
+        pool.refills.Add(1)
+        go pool.refill()
+    }
+}
+
+//the instances idle, borrowed and being started, which are Max at most.
+func (pool *Pool) live() (int) {
+    return len(pool.idle) + len(pool.borrowed) + pool.starting
+}
+
+//start an idle instance, which is quit if Max are there when it is started, as Borrow may start one meanwhile.
+//an error is logged and counted by Stats, the next recycling or Borrow starts one again.
+func (pool *Pool) refill() {
+    defer pool.refills.Done()
+    mso, err := pool.start()
+    if err != nil {
+        logError("Pool.refill", err, false, logged(err))
+    }
+    pool.mutex.Lock()
+    pool.starting --
+    if err == nil && ! pool.closed && (pool.opts.Max <= 0 || pool.live() < pool.opts.Max) {
+        pool.idle = append(pool.idle, mso)
+        mso = nil
+    }
+    pool.mutex.Unlock()
+    if mso != nil {
+        pool.recycle(mso)
+    }
+}
+
+//Stats counts the instances.
+func (pool *Pool) Stats() (PoolStats) {
+    pool.mutex.Lock()
+    defer pool.mutex.Unlock()
+    stats := pool.stats
+    stats.Idle, stats.Borrowed = len(pool.idle), len(pool.borrowed)
+    return stats
+}
+
+//Close quits the idle instances, the borrowed ones are quit on Return.
+func (pool *Pool) Close() {
+    pool.mutex.Lock()
+    idle := pool.idle
+    pool.idle, pool.closed = nil, true
+    pool.mutex.Unlock()
+    pool.refills.Wait()
+    for _, mso := range idle {
+        mso.Quit()
+    }
+}
@@ -0,0 +1,284 @@
+package excel
+
+import (
+    "context"
+    "errors"
+    "sync"
+    "testing"
+    "time"
+)
+
+//a Pool of fake instances without workbooks, New waits for gate if it is not nil.
+func fakePool(t *testing.T, opts PoolOptions, gate chan struct{}) (*Pool) {
+    opts.Option = Option{"Backend": "fake", "Visible": false}
+    opts.New = func(opt Option) (*MSO, error) {
+        if gate != nil {
+            <-gate
+        }
+        return initialize(opt)
+    }
+    pool, err := NewPool(opts)
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(pool.Close)
+    return pool
+}
+
+//wait for the instances started by recycling.
+func (pool *Pool) waitRefills() {
+    pool.refills.Wait()
+}
+
+func TestPoolBorrowReturn(t *testing.T) {
+    pool := fakePool(t, PoolOptions{Min: 1, Max: 2}, nil)
+    if stats := pool.Stats(); stats.Idle != 1 || stats.Created != 1 {
+        t.Fatalf("%+v after NewPool", stats)
+    }
+    mso, err := pool.Borrow(context.Background())
+    if err != nil {
+        t.Fatal(err)
+    }
+    mso.SetOption("Visible", true)
+    mso.AddWorkBook()
+    pool.Return(mso, nil)
+    if n := mso.CountWorkBooks(); n != 0 {
+        t.Fatalf("%v workbooks after Return", n)
+    }
+    if visible, _ := mso.IdExcel.GetProperty("Visible"); visible != false {
+        t.Fatalf("Visible is %v after Return", visible)
+    }
+    one, err := pool.Borrow(context.Background())
+    if err != nil {
+        t.Fatal(err)
+    }
+    if one != mso {
+        t.Fatal("the idle instance is not reused")
+    }
+    pool.Return(one, nil)
+    pool.Return(one, nil)
+    if stats := pool.Stats(); stats.Idle != 1 || stats.Borrowed != 0 || stats.Created != 1 {
+        t.Fatalf("%+v after Return twice", stats)
+    }
+}
+
+func TestPoolRecycle(t *testing.T) {
+    pool := fakePool(t, PoolOptions{Min: 1, MaxWorkBooks: 3}, nil)
+    mso, _ := pool.Borrow(context.Background())
+    pool.Return(mso, errors.New("failed"))
+    pool.waitRefills()
+    if stats := pool.Stats(); stats.Recycled != 1 || stats.Idle != 1 || stats.Created != 2 {
+        t.Fatalf("%+v after Return with an error", stats)
+    }
+    mso, _ = pool.Borrow(context.Background())
+    mso.AddWorkBook()
+    mso.AddWorkBook()
+    pool.Return(mso, nil)
+    if one, _ := pool.Borrow(context.Background()); one != mso {
+        t.Fatal("an instance of 2 workbooks is recycled")
+    }
+    mso.AddWorkBook()
+    pool.Return(mso, nil)
+    pool.waitRefills()
+    if stats := pool.Stats(); stats.Recycled != 2 || stats.Idle != 1 {
+        t.Fatalf("%+v after MaxWorkBooks", stats)
+    }
+}
+
+func TestPoolCheck(t *testing.T) {
+    bad := 1
+    pool := fakePool(t, PoolOptions{Min: 1, Check: func(mso *MSO) (error) {
+        if bad > 0 {
+            bad --
+            return errors.New("dead")
+        }
+        return nil
+    }}, nil)
+    mso, err := pool.Borrow(context.Background())
+    if err != nil || mso == nil {
+        t.Fatal(err)
+    }
+    if stats := pool.Stats(); stats.Recycled != 1 || stats.Borrowed != 1 {
+        t.Fatalf("%+v after a failed check", stats)
+    }
+    pool.Return(mso, nil)
+}
+
+func TestPoolCheckTimeout(t *testing.T) {
+    hang := make(chan struct{})
+    pool := fakePool(t, PoolOptions{Min: 1, Max: 1, Check: func(mso *MSO) (error) {
+        <-hang
+        return nil
+    }}, nil)
+    ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+    defer cancel()
+    if _, err := pool.Borrow(ctx); ! errors.Is(err, ErrTimeout) {
+        t.Fatalf("Borrow with a hanging check: %v", err)
+    }
+    ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+    defer cancel()
+    if _, err := pool.Borrow(ctx); ! errors.Is(err, ErrTimeout) {
+        t.Fatalf("Borrow while the timed out instance is checked: %v", err)
+    }
+    close(hang)
+    mso, err := pool.Borrow(context.Background())
+    if err != nil {
+        t.Fatal(err)
+    }
+    pool.waitRefills()
+    if stats := pool.Stats(); stats.Recycled < 1 || stats.Borrowed != 1 || stats.Created - stats.Recycled != 1 {
+        t.Fatalf("%+v after a timed out check", stats)
+    }
+    pool.Return(mso, nil)
+}
+
+func TestPoolStartTimeout(t *testing.T) {
+    gate := make(chan struct{})
+    pool := fakePool(t, PoolOptions{Max: 1}, gate)
+    ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+    defer cancel()
+    if _, err := pool.Borrow(ctx); ! errors.Is(err, ErrTimeout) {
+        t.Fatalf("Borrow with a hanging start: %v", err)
+    }
+    close(gate)
+    mso, err := pool.Borrow(context.Background())
+    if err != nil {
+        t.Fatal(err)
+    }
+    pool.Return(mso, nil)
+    if stats := pool.Stats(); stats.Created != 2 || stats.Recycled != 1 || stats.Idle != 1 {
+        t.Fatalf("%+v after a timed out start", stats)
+    }
+}
+
+func TestPoolMax(t *testing.T) {
+    pool := fakePool(t, PoolOptions{Max: 2}, nil)
+    a, _ := pool.Borrow(context.Background())
+    b, _ := pool.Borrow(context.Background())
+    ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+    defer cancel()
+    if _, err := pool.Borrow(ctx); ! errors.Is(err, ErrTimeout) {
+        t.Fatalf("Borrow over Max: %v", err)
+    }
+    var wg sync.WaitGroup
+    for i := 0; i < 8; i ++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            mso, err := pool.Borrow(context.Background())
+            if err != nil {
+                t.Error(err)
+                return
+            }
+            if stats := pool.Stats(); stats.Borrowed > 2 {
+                t.Errorf("%+v over Max", stats)
+            }
+            mso.AddWorkBook()
+            pool.Return(mso, nil)
+        }()
+    }
+    pool.Return(a, nil)
+    pool.Return(b, nil)
+    wg.Wait()
+    if stats := pool.Stats(); stats.Created != 2 {
+        t.Fatalf("%+v over Max", stats)
+    }
+}
+
+func TestPoolRefillMax(t *testing.T) {
+    gate := make(chan struct{}, 2)
+    gate <- struct{}{}
+    pool := fakePool(t, PoolOptions{Min: 1, Max: 1}, gate)
+    mso, _ := pool.Borrow(context.Background())
+    pool.Return(mso, errors.New("failed"))
+    borrowed := make(chan *MSO)
+    go func() {
+        mso, _ := pool.Borrow(context.Background())
+        borrowed <- mso
+    }()
+    gate <- struct{}{}
+    gate <- struct{}{}
+    mso = <-borrowed
+    pool.waitRefills()
+    if stats := pool.Stats(); stats.Idle + stats.Borrowed > 1 {
+        t.Fatalf("%+v over Max after refill", stats)
+    }
+    pool.Return(mso, nil)
+}
+
+func TestPoolClose(t *testing.T) {
+    pool := fakePool(t, PoolOptions{Min: 1}, nil)
+    mso, _ := pool.Borrow(context.Background())
+    pool.Close()
+    if _, err := pool.Borrow(context.Background()); ! errors.Is(err, ErrPoolClosed) {
+        t.Fatalf("Borrow after Close: %v", err)
+    }
+    pool.Return(mso, nil)
+    if _, err := mso.IdExcel.GetProperty("Version"); ! errors.Is(err, ErrDisconnected) {
+        t.Fatalf("an instance returned after Close is not quit: %v", err)
+    }
+    if stats := pool.Stats(); stats.Idle != 0 || stats.Borrowed != 0 {
+        t.Fatalf("%+v after Close", stats)
+    }
+}
+
+func TestPoolMinOverMax(t *testing.T) {
+    started := 0
+    opts := PoolOptions{Min: 3, Max: 2, Option: Option{"Backend": "fake"}, New: func(opt Option) (*MSO, error) {
+        started ++
+        return initialize(opt)
+    }}
+    if pool, err := NewPool(opts); err == nil || pool != nil || started != 0 {
+        t.Fatalf("Min 3 of Max 2 starts %v instances: %v", started, err)
+    }
+    opts.Max = 0
+    pool, err := NewPool(opts)
+    if err != nil || started != 3 {
+        t.Fatalf("Min 3 without Max starts %v instances: %v", started, err)
+    }
+    pool.Close()
+}
+
+func TestPoolRefillFailed(t *testing.T) {
+    var mutex sync.Mutex
+    fail := false
+    pool, err := NewPool(PoolOptions{Min: 1, Max: 2, Option: Option{"Backend": "fake"}, New: func(opt Option) (*MSO, error) {
+        mutex.Lock()
+        defer mutex.Unlock()
+        if fail {
+            return nil, errors.New("no excel")
+        }
+        return initialize(opt)
+    }})
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer pool.Close()
+    h := &recordHandler{}
+    withLogger(h, func() {
+        mso, _ := pool.Borrow(context.Background())
+        mutex.Lock()
+        fail = true
+        mutex.Unlock()
+        pool.Return(mso, errors.New("failed"))
+        pool.waitRefills()
+    })
+    if stats := pool.Stats(); stats.Failed != 1 || stats.Idle != 0 || stats.Recycled != 1 {
+        t.Fatalf("%+v after a failed refill", stats)
+    }
+    records := h.take()
+    if len(records) != 1 || recordAttrs(records[0])["op"] != "Pool.refill" {
+        t.Fatalf("%v records of a failed refill", len(records))
+    }
+    mutex.Lock()
+    fail = false
+    mutex.Unlock()
+    mso, err := pool.Borrow(context.Background())
+    if err != nil {
+        t.Fatal(err)
+    }
+    pool.Return(mso, nil)
+    if stats := pool.Stats(); stats.Created != 2 || stats.Idle != 1 {
+        t.Fatalf("%+v after Borrow", stats)
+    }
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+// +build !windows
+
+package excel
+
+import (
+    "errors"
+)
+
+//Excel only runs as a process on windows.
+func excelProcess(excel Dispatcher) (int, error) {
+    return 0, errors.New("Excel process needs windows")
+}
+
+//
+func killProcess(pid int) (error) {
+    return errors.New("Excel process needs windows")
+}
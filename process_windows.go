@@ -0,0 +1,36 @@
+package excel
+
+import (
+    "os"
+    "syscall"
+    "unsafe"
+)
+
+var (
+    moduser32, _ = syscall.LoadDLL("user32.dll")
+    procGetWindowThreadProcessId, _ = moduser32.FindProc("GetWindowThreadProcessId")
+)
+
+//the process id of Excel.Application by its Hwnd.
+func excelProcess(excel Dispatcher) (int, error) {
+    val, err := excel.GetProperty("Hwnd")
+    if err != nil {
+        return 0, err
+    }
+    hwnd, _ := toInt(val)
+    var pid uint32
+    if tid, _, err := procGetWindowThreadProcessId.Call(uintptr(uint32(hwnd)), uintptr(unsafe.Pointer(&pid))); tid == 0 {
+        return 0, err
+    }
+    return int(pid), nil
+}
+
+//
+func killProcess(pid int) (error) {
+    proc, err := os.FindProcess(pid)
+    if err != nil {
+        return err
+    }
+    defer proc.Release()
+    return proc.Kill()
+}
@@ -0,0 +1,372 @@
+package excel
+
+import (
+    "strconv"
+    "strings"
+    "errors"
+    "fmt"
+)
+
+//limits of a worksheet since Excel 2007.
+const (
+    MaxRows    = 1048576
+    MaxColumns = 16384
+)
+
+//ErrReference is wrapped by the errors of parsing references.
+var ErrReference = errors.New("incorrect reference")
+
+//CellRef is a cell reference, Row and Column are 1-based.
+//Row 0 is a whole column, Column 0 is a whole row.
+type CellRef struct {
+    Row       int
+    Column    int
+    AbsRow    bool
+    AbsColumn bool
+}
+
+//RangeRef is a range reference like 'My Sheet'!$A$1:C3, F:F or 3:3.
+type RangeRef struct {
+    Sheet string
+    From  CellRef
+    To    CellRef
+}
+
+//
+func refError(ref string, reason string) (error) {
+    return fmt.Errorf("%w %q: %v", ErrReference, ref, reason)
+}
+
+//ParseColumn parses column letters like "A", "xfd" or "$F".
+func ParseColumn(s string) (int, error) {
+    col := strings.TrimPrefix(s, "$")
+    if col == "" || len(col) > 3 {
+        return 0, refError(s, "want column letters A to XFD")
+    }
+    num := 0
+    for i := 0; i < len(col); i ++ {
+        ch := col[i] | 0x20
+        if ch < 'a' || ch > 'z' {
+            return 0, refError(s, "want column letters A to XFD")
+        }
+        num = num * 26 + int(ch - 'a') + 1
+    }
+    if num > MaxColumns {
+        return 0, refError(s, "column beyond XFD")
+    }
+    return num, nil
+}
+
+//ParseCellRef parses a cell in A1 notation like "B2" or "$B$2".
+func ParseCellRef(s string) (ref CellRef, err error) {
+    ref, rest, err := parseA1(s)
+    if err == nil && rest != "" {
+        err = refError(s, "unexpected "+rest)
+    }
+    if err == nil && (ref.Row == 0 || ref.Column == 0) {
+        err = refError(s, "want column and row")
+    }
+    return
+}
+
+//ParseRangeRef parses a range in A1 notation like "A1", "$A$1:C3", "F:F", "3:3" or "'My Sheet'!A1:C3".
+func ParseRangeRef(s string) (ref RangeRef, err error) {
+    body := s
+    if i := strings.LastIndex(s, "!"); i != -1 {
+        if ref.Sheet, err = parseSheetName(s[:i]); err != nil {
+            return
+        }
+        body = s[i+1:]
+    }
+    parts := strings.Split(body, ":")
+    if len(parts) > 2 {
+        return ref, refError(s, "more than one colon")
+    }
+    if ref.From, err = parseA1Part(s, parts[0]); err != nil {
+        return
+    }
+    ref.To = ref.From
+    if len(parts) == 2 {
+        if ref.To, err = parseA1Part(s, parts[1]); err != nil {
+            return
+        }
+    }
+    if (ref.From.Row == 0) != (ref.To.Row == 0) || (ref.From.Column == 0) != (ref.To.Column == 0) {
+        return ref, refError(s, "mixed whole column, whole row and cell")
+    }
+    if len(parts) == 1 && (ref.From.Row == 0 || ref.From.Column == 0) {
+        return ref, refError(s, "whole column or row wants a colon, like F:F or 3:3")
+    }
+    return
+}
+
+//
+func parseA1Part(s string, part string) (ref CellRef, err error) {
+    ref, rest, err := parseA1(part)
+    if err == nil && rest != "" {
+        err = refError(s, "unexpected "+rest)
+    }
+    return
+}
+
+//parse [$]letters[$]digits, either part may be missing.
+func parseA1(s string) (ref CellRef, rest string, err error) {
+    i := 0
+    if i < len(s) && s[i] == '$' {
+        ref.AbsColumn, i = true, i + 1
+    }
+    j := i
+    for j < len(s) && ((s[j] | 0x20) >= 'a' && (s[j] | 0x20) <= 'z') {
+        j ++
+    }
+    if j > i {
+        if ref.Column, err = ParseColumn(s[i:j]); err != nil {
+            return
+        }
+    } else if ref.AbsColumn {
+        ref.AbsColumn, ref.AbsRow = false, true
+    }
+    if j < len(s) && s[j] == '$' {
+        if ref.AbsRow {
+            return ref, "", refError(s, "duplicated $")
+        }
+        ref.AbsRow, j = true, j + 1
+    }
+    k := j
+    for k < len(s) && s[k] >= '0' && s[k] <= '9' {
+        k ++
+    }
+    if k > j {
+        row, _ := strconv.Atoi(s[j:k])
+        if row < 1 || row > MaxRows {
+            return ref, "", refError(s, "row beyond 1 to "+strconv.Itoa(MaxRows))
+        }
+        ref.Row = row
+    } else if ref.AbsRow {
+        return ref, "", refError(s, "$ without row")
+    }
+    if ref.Row == 0 && ref.Column == 0 {
+        return ref, "", refError(s, "want column or row")
+    }
+    return ref, s[k:], nil
+}
+
+//'My Sheet' or Sheet1, '' is an escaped quote.
+func parseSheetName(s string) (string, error) {
+    if strings.HasPrefix(s, "'") {
+        if len(s) < 2 || ! strings.HasSuffix(s, "'") {
+            return "", refError(s, "unclosed quote of sheet name")
+        }
+        name := s[1:len(s)-1]
+        if strings.Contains(strings.Replace(name, "''", "", -1), "'") {
+            return "", refError(s, "unescaped quote in sheet name")
+        }
+        s = strings.Replace(name, "''", "'", -1)
+    } else if s != "" && needQuote(s) {
+        return "", refError(s, "sheet name wants quotes")
+    }
+    if s == "" || len([]rune(s)) > 31 || strings.ContainsAny(s, `:\/?*[]`) {
+        return "", refError(s, "incorrect sheet name")
+    }
+    return s, nil
+}
+
+//sheet names which are not plain words or look like references are quoted.
+func needQuote(name string) (bool) {
+    for i, ch := range name {
+        word := ch == '_' || ch == '.' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || ch > 0x7f
+        if ! word && ! (i > 0 && ch >= '0' && ch <= '9') {
+            return true
+        }
+    }
+    if _, err := ParseCellRef(name); err == nil {
+        return true
+    }
+    if _, err := ParseR1C1(name, CellRef{Row: 1, Column: 1}); err == nil {
+        return true
+    }
+    return false
+}
+
+//
+func quoteSheet(name string) (string) {
+    if needQuote(name) {
+        return "'"+strings.Replace(name, "'", "''", -1)+"'"
+    }
+    return name
+}
+
+//String formats the cell in A1 notation.
+func (ref CellRef) String() (s string) {
+    if ref.Column > 0 {
+        if ref.AbsColumn {
+            s = "$"
+        }
+        s += ColumnItoa(ref.Column)
+    }
+    if ref.Row > 0 {
+        if ref.AbsRow {
+            s += "$"
+        }
+        s += strconv.Itoa(ref.Row)
+    }
+    return
+}
+
+//R1C1 formats the cell in R1C1 notation, relative parts are offsets from base.
+func (ref CellRef) R1C1(base CellRef) (s string) {
+    part := func(prefix string, n int, abs bool, b int) (string) {
+        if abs {
+            return prefix+strconv.Itoa(n)
+        } else if n == b {
+            return prefix
+        }
+        return prefix+"["+strconv.Itoa(n-b)+"]"
+    }
+    if ref.Row > 0 {
+        s = part("R", ref.Row, ref.AbsRow, base.Row)
+    }
+    if ref.Column > 0 {
+        s += part("C", ref.Column, ref.AbsColumn, base.Column)
+    }
+    return
+}
+
+//Bounds returns the first and last row and column, whole columns and rows are expanded.
+func (rg RangeRef) Bounds() (r1, c1, r2, c2 int) {
+    r1, c1, r2, c2 = rg.From.Row, rg.From.Column, rg.To.Row, rg.To.Column
+    if r1 == 0 || r2 == 0 {
+        r1, r2 = 1, MaxRows
+    }
+    if c1 == 0 || c2 == 0 {
+        c1, c2 = 1, MaxColumns
+    }
+    if r1 > r2 {
+        r1, r2 = r2, r1
+    }
+    if c1 > c2 {
+        c1, c2 = c2, c1
+    }
+    return
+}
+
+//
+func (rg RangeRef) Rows() (int) {
+    r1, _, r2, _ := rg.Bounds()
+    return r2 - r1 + 1
+}
+
+//
+func (rg RangeRef) Columns() (int) {
+    _, c1, _, c2 := rg.Bounds()
+    return c2 - c1 + 1
+}
+
+//String formats the range in A1 notation.
+func (rg RangeRef) String() (s string) {
+    if rg.Sheet != "" {
+        s = quoteSheet(rg.Sheet)+"!"
+    }
+    if rg.From == rg.To && rg.From.Row > 0 && rg.From.Column > 0 {
+        return s+rg.From.String()
+    }
+    return s+rg.From.String()+":"+rg.To.String()
+}
+
+//R1C1 formats the range in R1C1 notation, relative parts are offsets from base.
+func (rg RangeRef) R1C1(base CellRef) (s string) {
+    if rg.Sheet != "" {
+        s = quoteSheet(rg.Sheet)+"!"
+    }
+    if rg.From == rg.To {
+        return s+rg.From.R1C1(base)
+    }
+    return s+rg.From.R1C1(base)+":"+rg.To.R1C1(base)
+}
+
+//ParseR1C1 parses a range in R1C1 notation like "R1C1", "R[-1]C:R[1]C[2]", "C3" or "Sheet1!R2", relative parts are offsets from base.
+func ParseR1C1(s string, base CellRef) (ref RangeRef, err error) {
+    body := s
+    if i := strings.LastIndex(s, "!"); i != -1 {
+        if ref.Sheet, err = parseSheetName(s[:i]); err != nil {
+            return
+        }
+        body = s[i+1:]
+    }
+    parts := strings.Split(body, ":")
+    if len(parts) > 2 {
+        return ref, refError(s, "more than one colon")
+    }
+    if ref.From, err = parseR1C1Part(s, parts[0], base); err != nil {
+        return
+    }
+    ref.To = ref.From
+    if len(parts) == 2 {
+        if ref.To, err = parseR1C1Part(s, parts[1], base); err != nil {
+            return
+        }
+    }
+    if (ref.From.Row == 0) != (ref.To.Row == 0) || (ref.From.Column == 0) != (ref.To.Column == 0) {
+        return ref, refError(s, "mixed whole column, whole row and cell")
+    }
+    return
+}
+
+//
+func parseR1C1Part(s string, part string, base CellRef) (ref CellRef, err error) {
+    i := 0
+    axis := func(letter byte, b int) (n int, abs bool, ok bool) {
+        if i >= len(part) || (part[i] | 0x20) != letter {
+            return
+        }
+        i, ok = i + 1, true
+        if i < len(part) && part[i] == '[' {
+            j := strings.IndexByte(part[i:], ']')
+            if j == -1 {
+                err = refError(s, "unclosed [")
+                return
+            }
+            offset, e := strconv.Atoi(part[i+1:i+j])
+            if e != nil {
+                err = refError(s, "incorrect offset "+part[i:i+j+1])
+                return
+            }
+            n, i = b + offset, i + j + 1
+            return
+        }
+        j := i
+        for j < len(part) && part[j] >= '0' && part[j] <= '9' {
+            j ++
+        }
+        if j == i {
+            return b, false, true
+        }
+        n, _ = strconv.Atoi(part[i:j])
+        abs, i = true, j
+        return
+    }
+    row, absRow, hasRow := axis('r', base.Row)
+    if err != nil {
+        return
+    }
+    col, absCol, hasCol := axis('c', base.Column)
+    if err != nil {
+        return
+    }
+    if i != len(part) || (! hasRow && ! hasCol) {
+        return ref, refError(s, "want R and C parts")
+    }
+    if hasRow {
+        if row < 1 || row > MaxRows {
+            return ref, refError(s, "row beyond 1 to "+strconv.Itoa(MaxRows))
+        }
+        ref.Row, ref.AbsRow = row, absRow
+    }
+    if hasCol {
+        if col < 1 || col > MaxColumns {
+            return ref, refError(s, "column beyond 1 to "+strconv.Itoa(MaxColumns))
+        }
+        ref.Column, ref.AbsColumn = col, absCol
+    }
+    return
+}
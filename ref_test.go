@@ -0,0 +1,116 @@
+package excel
+
+import (
+    "errors"
+    "testing"
+)
+
+func TestParseCellRef(t *testing.T) {
+    tests := []struct {
+        in   string
+        want CellRef
+        out  string
+    }{
+        {"B2", CellRef{Row: 2, Column: 2}, "B2"},
+        {"$B$2", CellRef{Row: 2, Column: 2, AbsRow: true, AbsColumn: true}, "$B$2"},
+        {"b$2", CellRef{Row: 2, Column: 2, AbsRow: true}, "B$2"},
+        {"$AA10", CellRef{Row: 10, Column: 27, AbsColumn: true}, "$AA10"},
+        {"XFD1048576", CellRef{Row: MaxRows, Column: MaxColumns}, "XFD1048576"},
+    }
+    for _, test := range tests {
+        ref, err := ParseCellRef(test.in)
+        if err != nil || ref != test.want || ref.String() != test.out {
+            t.Errorf("%q is %+v (%q), %v, want %+v (%q)", test.in, ref, ref.String(), err, test.want, test.out)
+        }
+    }
+    for _, in := range []string{"", "XFE1", "A1048577", "A0", "F", "3", "$$A1", "A$", "A1B", "ABCD1", "1A"} {
+        if ref, err := ParseCellRef(in); ! errors.Is(err, ErrReference) {
+            t.Errorf("%q is %+v, %v, want ErrReference", in, ref, err)
+        }
+    }
+}
+
+func TestParseRangeRef(t *testing.T) {
+    tests := []struct {
+        in       string
+        sheet    string
+        from, to CellRef
+        out      string
+        rows     int
+        cols     int
+    }{
+        {"A1", "", CellRef{Row: 1, Column: 1}, CellRef{Row: 1, Column: 1}, "A1", 1, 1},
+        {"$A$1:C3", "", CellRef{Row: 1, Column: 1, AbsRow: true, AbsColumn: true}, CellRef{Row: 3, Column: 3}, "$A$1:C3", 3, 3},
+        {"C3:A1", "", CellRef{Row: 3, Column: 3}, CellRef{Row: 1, Column: 1}, "C3:A1", 3, 3},
+        {"F:F", "", CellRef{Column: 6}, CellRef{Column: 6}, "F:F", MaxRows, 1},
+        {"$B:$D", "", CellRef{Column: 2, AbsColumn: true}, CellRef{Column: 4, AbsColumn: true}, "$B:$D", MaxRows, 3},
+        {"3:3", "", CellRef{Row: 3}, CellRef{Row: 3}, "3:3", 1, MaxColumns},
+        {"$2:$5", "", CellRef{Row: 2, AbsRow: true}, CellRef{Row: 5, AbsRow: true}, "$2:$5", 4, MaxColumns},
+        {"Sheet1!A1:B2", "Sheet1", CellRef{Row: 1, Column: 1}, CellRef{Row: 2, Column: 2}, "Sheet1!A1:B2", 2, 2},
+        {"'My Sheet'!B2", "My Sheet", CellRef{Row: 2, Column: 2}, CellRef{Row: 2, Column: 2}, "'My Sheet'!B2", 1, 1},
+        {"'Bob''s ''x'''!A1", "Bob's 'x'", CellRef{Row: 1, Column: 1}, CellRef{Row: 1, Column: 1}, "'Bob''s ''x'''!A1", 1, 1},
+        {"'A1'!A1", "A1", CellRef{Row: 1, Column: 1}, CellRef{Row: 1, Column: 1}, "'A1'!A1", 1, 1},
+        {"'R2C3'!A1", "R2C3", CellRef{Row: 1, Column: 1}, CellRef{Row: 1, Column: 1}, "'R2C3'!A1", 1, 1},
+        {"A1:XFD1048576", "", CellRef{Row: 1, Column: 1}, CellRef{Row: MaxRows, Column: MaxColumns}, "A1:XFD1048576", MaxRows, MaxColumns},
+    }
+    for _, test := range tests {
+        rg, err := ParseRangeRef(test.in)
+        if err != nil || rg.Sheet != test.sheet || rg.From != test.from || rg.To != test.to {
+            t.Errorf("%q is %+v, %v", test.in, rg, err)
+            continue
+        }
+        if rg.String() != test.out || rg.Rows() != test.rows || rg.Columns() != test.cols {
+            t.Errorf("%q is %q of %v rows and %v columns, want %q of %v and %v", test.in, rg.String(), rg.Rows(), rg.Columns(), test.out, test.rows, test.cols)
+        }
+        if back, err := ParseRangeRef(rg.String()); err != nil || back != rg {
+            t.Errorf("%q does not round-trip: %+v, %v", rg.String(), back, err)
+        }
+    }
+    for _, in := range []string{"", "A1:XFE1", "A1:A1048577", "XFE:XFE", "1048577:1048577", "F", "3", "A1:F", "F:3",
+            "A1:B2:C3", "My Sheet!A1", "'My Sheet!A1", "'Bob's'!A1", "''!A1", "'a:b'!A1", "Sheet1!", "A1:"} {
+        if rg, err := ParseRangeRef(in); ! errors.Is(err, ErrReference) {
+            t.Errorf("%q is %+v, %v, want ErrReference", in, rg, err)
+        }
+    }
+}
+
+func TestParseR1C1(t *testing.T) {
+    base := CellRef{Row: 5, Column: 3}
+    tests := []struct {
+        in       string
+        sheet    string
+        from, to CellRef
+        a1       string
+    }{
+        {"R1C1", "", CellRef{Row: 1, Column: 1, AbsRow: true, AbsColumn: true}, CellRef{Row: 1, Column: 1, AbsRow: true, AbsColumn: true}, "$A$1"},
+        {"RC", "", CellRef{Row: 5, Column: 3}, CellRef{Row: 5, Column: 3}, "C5"},
+        {"R[-1]C[2]", "", CellRef{Row: 4, Column: 5}, CellRef{Row: 4, Column: 5}, "E4"},
+        {"r[-4]c[-2]", "", CellRef{Row: 1, Column: 1}, CellRef{Row: 1, Column: 1}, "A1"},
+        {"R[-1]C:R[1]C[2]", "", CellRef{Row: 4, Column: 3}, CellRef{Row: 6, Column: 5}, "C4:E6"},
+        {"R2C[-1]", "", CellRef{Row: 2, Column: 2, AbsRow: true}, CellRef{Row: 2, Column: 2, AbsRow: true}, "B$2"},
+        {"C3", "", CellRef{Column: 3, AbsColumn: true}, CellRef{Column: 3, AbsColumn: true}, "$C:$C"},
+        {"R2", "", CellRef{Row: 2, AbsRow: true}, CellRef{Row: 2, AbsRow: true}, "$2:$2"},
+        {"'My Sheet'!R[1]C", "My Sheet", CellRef{Row: 6, Column: 3}, CellRef{Row: 6, Column: 3}, "'My Sheet'!C6"},
+    }
+    for _, test := range tests {
+        rg, err := ParseR1C1(test.in, base)
+        if err != nil || rg.Sheet != test.sheet || rg.From != test.from || rg.To != test.to || rg.String() != test.a1 {
+            t.Errorf("%q is %+v (%q), %v, want %q", test.in, rg, rg.String(), err, test.a1)
+            continue
+        }
+        if back, err := ParseR1C1(rg.R1C1(base), base); err != nil || back != rg {
+            t.Errorf("%q does not round-trip by %q: %+v, %v", test.in, rg.R1C1(base), back, err)
+        }
+    }
+    if s := (CellRef{Row: 4, Column: 5}).R1C1(base); s != "R[-1]C[2]" {
+        t.Errorf("E4 from C5 is %q", s)
+    }
+    if s := (CellRef{Row: 5, Column: 1, AbsColumn: true}).R1C1(base); s != "RC1" {
+        t.Errorf("$A5 from C5 is %q", s)
+    }
+    for _, in := range []string{"", "R[-5]C", "RC[-3]", "R1048577C1", "R1C16385", "R[1C", "R[x]C", "X1", "R1C1:C2", "R1C1:R2C2:R3C3"} {
+        if rg, err := ParseR1C1(in, base); ! errors.Is(err, ErrReference) {
+            t.Errorf("%q is %+v, %v, want ErrReference", in, rg, err)
+        }
+    }
+}
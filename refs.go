@@ -0,0 +1,190 @@
+package excel
+
+import (
+    "fmt"
+    "runtime"
+    "sort"
+    "strings"
+    "sync"
+)
+
+//aptRef is an object handed out by an apartment, until it is released.
+type aptRef struct {
+    seq  int64
+    name string
+    site string
+    disp Dispatcher
+}
+
+//Leak is an object which has not been released, Site is where it was got, like "main.go:12 main.main".
+type Leak struct {
+    Name string
+    Site string
+}
+
+//
+func (leak Leak) String() (string) {
+    return leak.Name+" at "+leak.Site
+}
+
+//the package path of this package, to find the call site outside it.
+var packagePath = func() (string) {
+    pc, _, _, _ := runtime.Caller(0)
+    name := runtime.FuncForPC(pc).Name()
+    i := strings.LastIndex(name, "/") + 1
+    return name[:i+strings.Index(name[i:], ".")]
+}()
+
+//the first caller out of this package, tests of this package count as callers.
+func callSite() (string) {
+    pcs := make([]uintptr, 32)
+    frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+    for {
+        frame, more := frames.Next()
+        if ! strings.HasPrefix(frame.Function, packagePath+".") || strings.HasSuffix(frame.File, "_test.go") {
+            return fmt.Sprintf("%v:%v %v", frame.File, frame.Line, frame.Function)
+        }
+        if ! more {
+            return "unknown"
+        }
+    }
+}
+
+//
+func (apt *apartment) track(name string, disp Dispatcher) (*aptRef) {
+    ref := &aptRef{name: name, site: callSite(), disp: disp}
+    apt.mutex.Lock()
+    defer apt.mutex.Unlock()
+    apt.seq ++
+    ref.seq = apt.seq
+    apt.refs[ref] = struct{}{}
+    return ref
+}
+
+//false if it has been released.
+func (apt *apartment) untrack(ref *aptRef) (bool) {
+    apt.mutex.Lock()
+    defer apt.mutex.Unlock()
+    if _, ok := apt.refs[ref]; ! ok {
+        return false
+    }
+    delete(apt.refs, ref)
+    return true
+}
+
+//release the object if it has not been released.
+func (apt *apartment) release(ref *aptRef) (n int32) {
+    if apt.untrack(ref) {
+        apt.run(func() {
+            n = ref.disp.Release()
+        })
+    }
+    return
+}
+
+//the unreleased objects, the latest first.
+func (apt *apartment) outstanding() (refs []*aptRef) {
+    apt.mutex.Lock()
+    defer apt.mutex.Unlock()
+    for ref := range apt.refs {
+        refs = append(refs, ref)
+    }
+    sort.Slice(refs, func(i, j int) bool {
+        return refs[i].seq > refs[j].seq
+    })
+    return
+}
+
+//Leaks lists the objects got from the MSO and not released, with where they were got, the latest first.
+//Excel.Application and its Workbooks are in it until Quit.
+func (mso *MSO) Leaks() (leaks []Leak) {
+    if mso.apt == nil {
+        return
+    }
+    for _, ref := range mso.apt.outstanding() {
+        leaks = append(leaks, Leak{ref.name, ref.site})
+    }
+    return
+}
+
+//release the unreleased objects on Quit, the latest first and Excel.Application last.
+func (mso *MSO) releaseRefs() {
+    if mso.apt == nil {
+        return
+    }
+    for _, ref := range mso.apt.outstanding() {
+        mso.apt.release(ref)
+    }
+}
+
+//Scope is an MSO whose objects, and the objects got from them, are released at the end of MSO.Scope.
+type Scope struct {
+    *MSO
+    mutex sync.Mutex
+    refs  []*aptRef
+}
+
+//Scope calls fn with a Scope, the objects got from it in fn are released after fn, even by panic.
+//objects got from the MSO itself or other scopes are not.
+func (mso *MSO) Scope(fn func(scope *Scope)) {
+    scope := &Scope{}
+    one := *mso
+    one.IdExcel, one.IdWorkBooks = scope.alias(mso.IdExcel), scope.alias(mso.IdWorkBooks)
+    one.WorkBook = WorkBook{scope.alias(mso.WorkBook.Dispatcher), &one}
+    scope.MSO = &one
+    defer scope.release()
+    fn(scope)
+}
+
+//the object of the scope, which is not released with it.
+func (scope *Scope) alias(disp Dispatcher) (Dispatcher) {
+    if ad, ok := disp.(aptDispatch); ok {
+        ad.scope = scope
+        return ad
+    }
+    return disp
+}
+
+//Track adds objects got elsewhere to the scope, so they are released with it.
+func (scope *Scope) Track(disps... Dispatcher) {
+    for _, disp := range disps {
+        if ad, ok := aptOf(disp); ok && ad.ref != nil {
+            scope.add(ad.ref)
+        }
+    }
+}
+
+//
+func (scope *Scope) add(ref *aptRef) {
+    scope.mutex.Lock()
+    defer scope.mutex.Unlock()
+    scope.refs = append(scope.refs, ref)
+}
+
+//release the latest first.
+func (scope *Scope) release() {
+    scope.mutex.Lock()
+    refs := scope.refs
+    scope.refs = nil
+    scope.mutex.Unlock()
+    for i := len(refs)-1; i >= 0; i -- {
+        scope.apt.release(refs[i])
+    }
+}
+
+//the aptDispatch in WorkBook, Sheet, Range and Cell.
+func aptOf(disp Dispatcher) (aptDispatch, bool) {
+    switch one := disp.(type) {
+        case WorkBook:
+            return aptOf(one.Dispatcher)
+        case Sheet:
+            return aptOf(one.Dispatcher)
+        case Range:
+            return aptOf(one.Dispatcher)
+        case Cell:
+            return aptOf(one.Dispatcher)
+        case aptDispatch:
+            return one, true
+    }
+    return aptDispatch{}, false
+}
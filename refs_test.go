@@ -0,0 +1,77 @@
+package excel
+
+import (
+    "strings"
+    "testing"
+)
+
+func TestLeaksOfGetIDispatch(t *testing.T) {
+    mso, err := New(Option{"Backend": "fake"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer mso.Quit()
+    base := len(mso.Leaks())
+    sheet, err := mso.AddSheet("two")
+    if err != nil {
+        t.Fatal(err)
+    }
+    leaks := mso.Leaks()
+    if len(leaks) != base+1 || ! strings.Contains(leaks[0].Site, "refs_test.go") {
+        t.Fatalf("sheet added is not tracked: %v", leaks)
+    }
+    cells := GetIDispatch(sheet, "Cells")
+    if leaks = mso.Leaks(); len(leaks) != base+2 || leaks[0].Name != "Cells" {
+        t.Fatalf("GetIDispatch is not tracked: %v", leaks)
+    }
+    cells.Release()
+    sheet.Release()
+    if leaks = mso.Leaks(); len(leaks) != base {
+        t.Fatalf("released objects are tracked: %v", leaks)
+    }
+}
+
+func TestScopeOfGetIDispatch(t *testing.T) {
+    mso, err := New(Option{"Backend": "fake"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer mso.Quit()
+    base := len(mso.Leaks())
+    var fake *FakeDispatch
+    mso.Scope(func(scope *Scope) {
+        sheet, err := scope.AddSheet("two")
+        if err != nil {
+            t.Fatal(err)
+        }
+        font := GetIDispatch(sheet, "Cells", "Font")
+        fake = unwrap(font).(*FakeDispatch)
+        if n := len(mso.Leaks()); n != base+2 {
+            t.Fatalf("%v objects in the scope, want 2: %v", n-base, mso.Leaks())
+        }
+    })
+    if leaks := mso.Leaks(); len(leaks) != base {
+        t.Fatalf("objects of the scope are not released: %v", leaks)
+    }
+    if fake.Released != 1 {
+        t.Fatalf("Font is released %v times", fake.Released)
+    }
+}
+
+func TestQuitReleasesGetIDispatch(t *testing.T) {
+    mso, err := New(Option{"Backend": "fake"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    sheet, err := mso.AddSheet()
+    if err != nil {
+        t.Fatal(err)
+    }
+    cells := unwrap(GetIDispatch(sheet, "Cells")).(*FakeDispatch)
+    if err := mso.Quit(); err != nil {
+        t.Fatal(err)
+    }
+    if cells.Released != 1 || len(mso.Leaks()) != 0 {
+        t.Fatalf("Cells is released %v times on Quit, leaks: %v", cells.Released, mso.Leaks())
+    }
+}
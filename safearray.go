@@ -0,0 +1,70 @@
+package excel
+
+import (
+    "fmt"
+    "reflect"
+)
+
+//is2D reports if val is a 2-D slice or array like [][]interface{}, [][]string, [][]float64 or [][3]int,
+//[]interface{} of slices counts too.
+func is2D(val interface{}) (bool) {
+    rv := reflect.ValueOf(val)
+    if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+        return false
+    }
+    switch rv.Type().Elem().Kind() {
+        case reflect.Slice, reflect.Array:
+            return true
+        case reflect.Interface:
+            return rv.Len() > 0 && isRow(rv.Index(0))
+    }
+    return false
+}
+
+//the value in an interface{} element of a slice, or the element itself.
+func elemOf(val reflect.Value) (reflect.Value) {
+    if val.Kind() == reflect.Interface {
+        return val.Elem()
+    }
+    return val
+}
+
+//an element which is a row, a slice or array.
+func isRow(val reflect.Value) (bool) {
+    kind := elemOf(val).Kind()
+    return kind == reflect.Slice || kind == reflect.Array
+}
+
+//arrayRows converts a 2-D slice to rows of cell values for a 2-D SAFEARRAY (1 To rows, 1 To columns),
+//short rows are padded with nil which is VT_EMPTY, columns is the longest row.
+func arrayRows(val interface{}) (rows [][]interface{}, columns int, err error) {
+    if ! is2D(val) {
+        return nil, 0, fmt.Errorf("want 2-D slice, got %T", val)
+    }
+    rv := reflect.ValueOf(val)
+    rows = make([][]interface{}, rv.Len())
+    for i := range rows {
+        row := elemOf(rv.Index(i))
+        if ! row.IsValid() {
+            continue
+        } else if row.Kind() != reflect.Slice && row.Kind() != reflect.Array {
+            return nil, 0, fmt.Errorf("row %v is %v, not a slice", i+1, row.Type())
+        }
+        rows[i] = make([]interface{}, row.Len())
+        for j := range rows[i] {
+            rows[i][j] = cellValue(row.Index(j))
+        }
+        if len(rows[i]) > columns {
+            columns = len(rows[i])
+        }
+    }
+    for i, row := range rows {
+        if len(row) < columns {
+            rows[i] = append(row, make([]interface{}, columns-len(row))...)
+        }
+    }
+    if len(rows) > MaxRows || columns > MaxColumns {
+        return nil, 0, fmt.Errorf("%v rows and %v columns are beyond a sheet", len(rows), columns)
+    }
+    return
+}
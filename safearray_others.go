@@ -0,0 +1,19 @@
+//go:build !windows
+// +build !windows
+
+package excel
+
+import (
+    "errors"
+    "github.com/go-ole/go-ole"
+)
+
+//SAFEARRAY only comes from COM on windows.
+func ToValueArray(sac *ole.SafeArrayConversion) (values [][]interface{}) {
+    return
+}
+
+//
+func safeArrayFrom(val interface{}) (*ole.SafeArray, error) {
+    return nil, errors.New("SAFEARRAY needs windows")
+}
@@ -0,0 +1,58 @@
+package excel
+
+import (
+    "reflect"
+    "testing"
+)
+
+func TestIs2D(t *testing.T) {
+    tests := []struct {
+        val  interface{}
+        want bool
+    }{
+        {nil, false},
+        {"ab", false},
+        {[]string{"a"}, false},
+        {[]interface{}{}, false},
+        {[]interface{}{nil, []int{1}}, false},
+        {[]interface{}{1, []int{1}}, false},
+        {[][]float64{}, true},
+        {[][]string{{"a"}}, true},
+        {[2][2]int{}, true},
+        {[][2]float64{{1, 2}}, true},
+        {[]interface{}{[]int{1}}, true},
+        {[]interface{}{[]interface{}{"a", 1}}, true},
+    }
+    for _, test := range tests {
+        if got := is2D(test.val); got != test.want {
+            t.Errorf("is2D(%#v) = %v, want %v", test.val, got, test.want)
+        }
+    }
+}
+
+func TestArrayRows(t *testing.T) {
+    tests := []struct {
+        val     interface{}
+        rows    [][]interface{}
+        columns int
+    }{
+        {[][]string{{"a", "b"}, {"c"}, nil}, [][]interface{}{{"a", "b"}, {"c", nil}, {nil, nil}}, 2},
+        {[][]float64{{1.5}, {2, 3, 4}}, [][]interface{}{{1.5, nil, nil}, {2.0, 3.0, 4.0}}, 3},
+        {[][2]float64{{1, 2}}, [][]interface{}{{1.0, 2.0}}, 2},
+        {[][]int{{1, 2}, {}}, [][]interface{}{{int64(1), int64(2)}, {nil, nil}}, 2},
+        {[]interface{}{[]int{1, 2}, []string{"x"}, nil}, [][]interface{}{{int64(1), int64(2)}, {"x", nil}, {nil, nil}}, 2},
+        {[][]interface{}{{nil, true}, {"a"}}, [][]interface{}{{nil, true}, {"a", nil}}, 2},
+        {[][]string{}, [][]interface{}{}, 0},
+    }
+    for _, test := range tests {
+        rows, columns, err := arrayRows(test.val)
+        if err != nil || columns != test.columns || ! reflect.DeepEqual(rows, test.rows) {
+            t.Errorf("arrayRows(%#v) = %v, %v, %v, want %v, %v", test.val, rows, columns, err, test.rows, test.columns)
+        }
+    }
+    for _, val := range []interface{}{[]interface{}{[]int{1}, 3}, []int{1}, "ab"} {
+        if _, _, err := arrayRows(val); err == nil {
+            t.Errorf("arrayRows(%#v) is accepted", val)
+        }
+    }
+}
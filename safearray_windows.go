@@ -0,0 +1,93 @@
+package excel
+
+import (
+    "syscall"
+    "unsafe"
+    "errors"
+    "github.com/go-ole/go-ole"
+)
+
+var (
+    modoleaut32, _ = syscall.LoadDLL("oleaut32.dll")
+    procSafeArrayGetElement, _ = modoleaut32.FindProc("SafeArrayGetElement")
+    procSafeArrayGetVartype, _ = modoleaut32.FindProc("SafeArrayGetVartype")
+    procSafeArrayCreate, _ = modoleaut32.FindProc("SafeArrayCreate")
+    procSafeArrayPutElement, _ = modoleaut32.FindProc("SafeArrayPutElement")
+)
+
+//from github.com/go-ole/go-ole/utility.go:convertHresultToError
+// convertHresultToError converts syscall to error, if call is unsuccessful.
+func convertHresultToError(hr uintptr, r2 uintptr, ignore error) (err error) {
+    if hr != 0 {
+        err = ole.NewError(hr)
+    }
+    return
+}
+
+//from github.com/go-ole/go-ole/safearray_windows.go:safeArrayGetVartype
+// AKA: SafeArrayGetVartype in Windows API.
+func safeArrayGetVartype(safearray *ole.SafeArray) (varType uint16, err error) {
+    err = convertHresultToError(
+        procSafeArrayGetVartype.Call(
+            uintptr(unsafe.Pointer(safearray)),
+            uintptr(unsafe.Pointer(&varType))))
+    return
+}
+
+//from github.com/go-ole/go-ole/safearray_windows.go:safeArrayGetElement
+// safeArrayGetElement retrieves element at given index.
+func safeArrayGetElement(safearray *ole.SafeArray, index [2]int32, pv unsafe.Pointer) error {
+    indexPtr := unsafe.Pointer(&index[0])
+    return convertHresultToError(
+        procSafeArrayGetElement.Call(
+            uintptr(unsafe.Pointer(safearray)),
+            uintptr(indexPtr),
+            uintptr(pv)))
+}
+
+//from github.com/go-ole/go-ole/safearrayconversion.go:ToValueArray
+func ToValueArray(sac *ole.SafeArrayConversion) (values [][]interface{}) {
+    totalElements1, _ := sac.TotalElements(1)
+    totalElements2, _ := sac.TotalElements(2)
+    te1, te2 := int(totalElements1), int(totalElements2)
+
+    values = make([][]interface{}, te1)
+    for i := 0; i < te1; i ++ {
+        row := make([]interface{}, te2)
+        for j := 0; j < te2; j ++ {
+            var v ole.VARIANT
+            safeArrayGetElement(sac.Array, [2]int32 {int32(i)+1, int32(j)+1}, unsafe.Pointer(&v))
+            row[j] = (VARIANT{&v}).Value()
+        }
+        values[i] = row
+    }
+
+    return
+}
+
+//the write-side of ToValueArray: a 2-D SAFEARRAY of VT_VARIANT from a 2-D slice, see arrayRows.
+func safeArrayFrom(val interface{}) (*ole.SafeArray, error) {
+    rows, cols, err := arrayRows(val)
+    if err != nil {
+        return nil, err
+    }
+    bounds := [2]ole.SafeArrayBound{{Elements: uint32(len(rows)), LowerBound: 1}, {Elements: uint32(cols), LowerBound: 1}}
+    sa, _, _ := procSafeArrayCreate.Call(uintptr(ole.VT_VARIANT), 2, uintptr(unsafe.Pointer(&bounds[0])))
+    if sa == 0 {
+        return nil, errors.New("SafeArrayCreate failed")
+    }
+    array := *(**ole.SafeArray)(unsafe.Pointer(&sa))
+    for i, row := range rows {
+        for j, val := range row {
+            va := variantOf(val)
+            index := [2]int32{int32(i)+1, int32(j)+1}
+            err := convertHresultToError(procSafeArrayPutElement.Call(sa, uintptr(unsafe.Pointer(&index[0])), uintptr(unsafe.Pointer(&va))))
+            ole.VariantClear(&va)
+            if err != nil {
+                (&ole.SafeArrayConversion{Array: array}).Release()
+                return nil, err
+            }
+        }
+    }
+    return array, nil
+}
@@ -0,0 +1,474 @@
+package excel
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+)
+
+//Toggle is a boolean property of a style, Unchanged is not applied.
+type Toggle int
+
+//values of Toggle.
+const (
+    Unchanged Toggle = iota
+    On
+    Off
+)
+
+//values of XlHAlign, XlVAlign, XlOrientation, XlLineStyle, XlBorderWeight, XlUnderlineStyle, XlPattern and XlColorIndex.
+const (
+    XlHAlignGeneral               = 1
+    XlHAlignLeft                  = -4131
+    XlHAlignCenter                = -4108
+    XlHAlignRight                 = -4152
+    XlHAlignFill                  = 5
+    XlHAlignJustify               = -4130
+    XlHAlignCenterAcrossSelection = 7
+    XlHAlignDistributed           = -4117
+
+    XlVAlignTop                   = -4160
+    XlVAlignCenter                = -4108
+    XlVAlignBottom                = -4107
+    XlVAlignJustify               = -4130
+    XlVAlignDistributed           = -4117
+
+    XlOrientationDownward         = -4170
+    XlOrientationHorizontal       = -4128
+    XlOrientationUpward           = -4171
+    XlOrientationVertical         = -4166
+
+    XlContinuous                  = 1
+    XlDash                        = -4115
+    XlDashDot                     = 4
+    XlDashDotDot                  = 5
+    XlDot                         = -4118
+    XlDouble                      = -4119
+    XlSlantDashDot                = 13
+    XlLineStyleNone               = -4142
+
+    XlHairline                    = 1
+    XlThin                        = 2
+    XlMedium                      = -4138
+    XlThick                       = 4
+
+    XlUnderlineStyleNone             = -4142
+    XlUnderlineStyleSingle           = 2
+    XlUnderlineStyleDouble           = -4119
+    XlUnderlineStyleSingleAccounting = 4
+    XlUnderlineStyleDoubleAccounting = 5
+
+    XlPatternSolid                = 1
+    XlPatternGray50               = -4125
+    XlPatternGray75               = -4126
+    XlPatternGray25               = -4124
+    XlPatternNone                 = -4142
+    XlPatternAutomatic            = -4105
+    XlPatternChecker              = 9
+    XlPatternCrissCross           = 16
+    XlPatternDown                 = -4121
+    XlPatternGray16               = 17
+    XlPatternGray8                = 18
+    XlPatternGrid                 = 15
+    XlPatternHorizontal           = -4128
+    XlPatternLightDown            = 13
+    XlPatternLightHorizontal      = 11
+    XlPatternLightUp              = 14
+    XlPatternLightVertical        = 12
+    XlPatternSemiGray75           = 10
+    XlPatternUp                   = -4162
+    XlPatternVertical             = -4166
+
+    XlColorIndexNone              = -4142
+    XlColorIndexAutomatic         = -4105
+)
+
+//Font of a range, zero values and Unchanged are not applied.
+//Color is like "#FF0000", ColorIndex is of the palette, 1 to 56.
+type Font struct {
+    Name          string
+    Size          float64
+    Bold          Toggle
+    Italic        Toggle
+    Underline     int       //XlUnderlineStyle
+    Strikethrough Toggle
+    Color         string
+    ColorIndex    int
+}
+
+//Interior of a range, zero values are not applied.
+type Interior struct {
+    Pattern      int        //XlPattern
+    Color        string
+    ColorIndex   int
+    PatternColor string
+}
+
+//Border is an edge of a range, zero values are not applied.
+type Border struct {
+    LineStyle  int          //XlLineStyle
+    Weight     int          //XlBorderWeight
+    Color      string
+    ColorIndex int
+}
+
+//Borders of a range, All is applied before the edges, and not read.
+type Borders struct {
+    All              Border
+    Left             Border
+    Top              Border
+    Bottom           Border
+    Right            Border
+    DiagonalDown     Border
+    DiagonalUp       Border
+    InsideVertical   Border
+    InsideHorizontal Border
+}
+
+//Alignment of a range, zero values and Unchanged are not applied.
+type Alignment struct {
+    Horizontal  int         //XlHAlign
+    Vertical    int         //XlVAlign
+    WrapText    Toggle
+    ShrinkToFit Toggle
+    Orientation int         //degrees from -90 to 90, or XlOrientation, XlOrientationHorizontal for 0 degrees
+    IndentLevel int
+    MergeCells  Toggle
+}
+
+//Style of a range, applied in the order of the fields and of their fields.
+type Style struct {
+    NumberFormat string
+    Font         Font
+    Interior     Interior
+    Borders      Borders
+    Alignment    Alignment
+}
+
+//styleProp is a property of a style, which is put in order.
+type styleProp struct {
+    name string
+    val  interface{}
+}
+
+//the values of the enumerations checked before put.
+var (
+    underlineStyles = []int{XlUnderlineStyleNone, XlUnderlineStyleSingle, XlUnderlineStyleDouble, XlUnderlineStyleSingleAccounting, XlUnderlineStyleDoubleAccounting}
+    lineStyles      = []int{XlContinuous, XlDash, XlDashDot, XlDashDotDot, XlDot, XlDouble, XlSlantDashDot, XlLineStyleNone}
+    borderWeights   = []int{XlHairline, XlThin, XlMedium, XlThick}
+    hAligns         = []int{XlHAlignGeneral, XlHAlignLeft, XlHAlignCenter, XlHAlignRight, XlHAlignFill, XlHAlignJustify, XlHAlignCenterAcrossSelection, XlHAlignDistributed}
+    vAligns         = []int{XlVAlignTop, XlVAlignCenter, XlVAlignBottom, XlVAlignJustify, XlVAlignDistributed}
+    orientations    = []int{XlOrientationDownward, XlOrientationHorizontal, XlOrientationUpward, XlOrientationVertical}
+    patterns        = []int{XlPatternSolid, XlPatternGray50, XlPatternGray75, XlPatternGray25, XlPatternNone, XlPatternAutomatic,
+        XlPatternChecker, XlPatternCrissCross, XlPatternDown, XlPatternGray16, XlPatternGray8, XlPatternGrid, XlPatternHorizontal,
+        XlPatternLightDown, XlPatternLightHorizontal, XlPatternLightUp, XlPatternLightVertical, XlPatternSemiGray75, XlPatternUp, XlPatternVertical}
+)
+
+//the edges of Borders with XlBordersIndex.
+func (borders *Borders) edges() ([]*Border, []int) {
+    return []*Border{&borders.Left, &borders.Top, &borders.Bottom, &borders.Right, &borders.DiagonalDown, &borders.DiagonalUp, &borders.InsideVertical, &borders.InsideHorizontal},
+        []int{7, 8, 9, 10, 5, 6, 11, 12}
+}
+
+//styleProps collects the properties to put, and the first error of their values.
+type styleProps struct {
+    props []styleProp
+    err   error
+}
+
+//
+func (sp *styleProps) add(name string, val interface{}) {
+    sp.props = append(sp.props, styleProp{name, val})
+}
+
+//
+func (sp *styleProps) toggle(name string, val Toggle) {
+    switch val {
+        case Unchanged:
+        case On:
+            sp.add(name, true)
+        case Off:
+            sp.add(name, false)
+        default:
+            sp.fail(name, val)
+    }
+}
+
+//
+func (sp *styleProps) enum(name string, val int, vals []int) {
+    if val == 0 {
+        return
+    }
+    for _, one := range vals {
+        if one == val {
+            sp.add(name, val)
+            return
+        }
+    }
+    sp.fail(name, val)
+}
+
+//
+func (sp *styleProps) color(name string, val string) {
+    if val == "" {
+        return
+    }
+    if rgb, err := colorValue(val); err != nil {
+        sp.fail(name, val)
+    } else {
+        sp.add(name, rgb)
+    }
+}
+
+//
+func (sp *styleProps) colorIndex(name string, val int) {
+    if val == 0 {
+        return
+    }
+    if val == XlColorIndexNone || val == XlColorIndexAutomatic || val >= 1 && val <= 56 {
+        sp.add(name, val)
+    } else {
+        sp.fail(name, val)
+    }
+}
+
+//
+func (sp *styleProps) fail(name string, val interface{}) {
+    if sp.err == nil {
+        sp.err = fmt.Errorf("incorrect %v %+v", name, val)
+    }
+}
+
+//
+func (font Font) props() (sp styleProps) {
+    if font.Name != "" {
+        sp.add("Name", font.Name)
+    }
+    if font.Size < 0 {
+        sp.fail("Size", font.Size)
+    } else if font.Size > 0 {
+        sp.add("Size", font.Size)
+    }
+    sp.toggle("Bold", font.Bold)
+    sp.toggle("Italic", font.Italic)
+    sp.enum("Underline", font.Underline, underlineStyles)
+    sp.toggle("Strikethrough", font.Strikethrough)
+    sp.color("Color", font.Color)
+    sp.colorIndex("ColorIndex", font.ColorIndex)
+    return
+}
+
+//
+func (interior Interior) props() (sp styleProps) {
+    sp.enum("Pattern", interior.Pattern, patterns)
+    sp.color("Color", interior.Color)
+    sp.colorIndex("ColorIndex", interior.ColorIndex)
+    sp.color("PatternColor", interior.PatternColor)
+    return
+}
+
+//
+func (border Border) props() (sp styleProps) {
+    sp.enum("LineStyle", border.LineStyle, lineStyles)
+    sp.enum("Weight", border.Weight, borderWeights)
+    sp.color("Color", border.Color)
+    sp.colorIndex("ColorIndex", border.ColorIndex)
+    return
+}
+
+//the properties of the range itself.
+func (alignment Alignment) props() (sp styleProps) {
+    sp.enum("HorizontalAlignment", alignment.Horizontal, hAligns)
+    sp.enum("VerticalAlignment", alignment.Vertical, vAligns)
+    sp.toggle("WrapText", alignment.WrapText)
+    sp.toggle("ShrinkToFit", alignment.ShrinkToFit)
+    if alignment.Orientation >= -90 && alignment.Orientation <= 90 {
+        if alignment.Orientation != 0 {
+            sp.add("Orientation", alignment.Orientation)
+        }
+    } else {
+        sp.enum("Orientation", alignment.Orientation, orientations)
+    }
+    if alignment.IndentLevel < 0 || alignment.IndentLevel > 250 {
+        sp.fail("IndentLevel", alignment.IndentLevel)
+    } else if alignment.IndentLevel > 0 {
+        sp.add("IndentLevel", alignment.IndentLevel)
+    }
+    sp.toggle("MergeCells", alignment.MergeCells)
+    return
+}
+
+//"#RRGGBB" as Color of Excel, which is 0xBBGGRR.
+func colorValue(s string) (int, error) {
+    if len(s) != 7 || s[0] != '#' {
+        return 0, fmt.Errorf("incorrect color %q, want #RRGGBB", s)
+    }
+    n, err := strconv.ParseUint(s[1:], 16, 32)
+    if err != nil {
+        return 0, fmt.Errorf("incorrect color %q, want #RRGGBB", s)
+    }
+    return int(n>>16 | n&0xFF00 | (n&0xFF)<<16), nil
+}
+
+//Color of Excel as "#RRGGBB", "" for mixed colors of a range.
+func colorString(val interface{}) (string) {
+    n, ok := toInt(val)
+    if ! ok || n < 0 || n > 0xFFFFFF {
+        return ""
+    }
+    return fmt.Sprintf("#%02X%02X%02X", n&0xFF, n>>8&0xFF, n>>16)
+}
+
+//a part of a style to put into the object got by name and params, "" for the range itself.
+type stylePart struct {
+    name   string
+    params []interface{}
+    sp     styleProps
+}
+
+//the parts of style in order.
+func (style Style) parts() (parts []stylePart, err error) {
+    var sp styleProps
+    if style.NumberFormat != "" {
+        sp.add("NumberFormat", style.NumberFormat)
+    }
+    parts = append(parts, stylePart{"", nil, sp})
+    parts = append(parts, stylePart{"Font", nil, style.Font.props()})
+    parts = append(parts, stylePart{"Interior", nil, style.Interior.props()})
+    parts = append(parts, stylePart{"Borders", nil, style.Borders.All.props()})
+    edges, indexes := style.Borders.edges()
+    for i, edge := range edges {
+        parts = append(parts, stylePart{"Borders", []interface{}{indexes[i]}, edge.props()})
+    }
+    parts = append(parts, stylePart{"", nil, style.Alignment.props()})
+    for _, part := range parts {
+        if part.sp.err != nil {
+            return nil, fmt.Errorf("%v: %w", strings.TrimSpace("style "+part.name), part.sp.err)
+        }
+    }
+    return
+}
+
+//put the style into a range or cell, the objects without properties to put are not got.
+func applyStyle(disp Dispatcher, style Style) (err error) {
+    parts, err := style.parts()
+    if err != nil {
+        return
+    }
+    for _, part := range parts {
+        if len(part.sp.props) == 0 {
+            continue
+        }
+        obj := disp
+        if part.name != "" {
+            obj = mustGetDispatcher(disp, part.name, part.params...)
+            defer DoFuncs(obj.Release)
+        }
+        for _, prop := range part.sp.props {
+            mustPut(obj, prop.name, prop.val)
+        }
+    }
+    return
+}
+
+//ApplyStyle puts the style into the range in a fixed order, the values are checked before any is put.
+func (rg Range) ApplyStyle(style Style) (err error) {
+    defer Except("Range.ApplyStyle", &err)
+    return applyStyle(rg.Dispatcher, style)
+}
+
+//ApplyStyle puts the style into the cell in a fixed order, the values are checked before any is put.
+func (cell Cell) ApplyStyle(style Style) (err error) {
+    defer Except("Cell.ApplyStyle", &err)
+    return applyStyle(cell.Dispatcher, style)
+}
+
+//ReadStyle gets the style of the range, properties which differ in the range are zero.
+//a color is read as Color, or as ColorIndex if it is none or automatic.
+func (rg Range) ReadStyle() (style Style, err error) {
+    defer Except("Range.ReadStyle", &err)
+    return readStyle(rg.Dispatcher), nil
+}
+
+//ReadStyle gets the style of the cell.
+func (cell Cell) ReadStyle() (style Style, err error) {
+    defer Except("Cell.ReadStyle", &err)
+    return readStyle(cell.Dispatcher), nil
+}
+
+//
+func readStyle(disp Dispatcher) (style Style) {
+    style.NumberFormat, _ = mustGet(disp, "NumberFormat").(string)
+    font := mustGetDispatcher(disp, "Font")
+    defer font.Release()
+    style.Font = Font{
+        Name:          String(mustGet(font, "Name")),
+        Size:          styleFloat(mustGet(font, "Size")),
+        Bold:          styleToggle(mustGet(font, "Bold")),
+        Italic:        styleToggle(mustGet(font, "Italic")),
+        Underline:     styleInt(mustGet(font, "Underline")),
+        Strikethrough: styleToggle(mustGet(font, "Strikethrough")),
+    }
+    style.Font.Color, style.Font.ColorIndex = readColor(font)
+    interior := mustGetDispatcher(disp, "Interior")
+    defer interior.Release()
+    style.Interior = Interior{
+        Pattern:      styleInt(mustGet(interior, "Pattern")),
+        PatternColor: colorString(mustGet(interior, "PatternColor")),
+    }
+    style.Interior.Color, style.Interior.ColorIndex = readColor(interior)
+    edges, indexes := style.Borders.edges()
+    for i, edge := range edges {
+        border := mustGetDispatcher(disp, "Borders", indexes[i])
+        *edge = Border{
+            LineStyle:  styleInt(mustGet(border, "LineStyle")),
+            Weight:     styleInt(mustGet(border, "Weight")),
+        }
+        edge.Color, edge.ColorIndex = readColor(border)
+        border.Release()
+    }
+    style.Alignment = Alignment{
+        Horizontal:  styleInt(mustGet(disp, "HorizontalAlignment")),
+        Vertical:    styleInt(mustGet(disp, "VerticalAlignment")),
+        WrapText:    styleToggle(mustGet(disp, "WrapText")),
+        ShrinkToFit: styleToggle(mustGet(disp, "ShrinkToFit")),
+        Orientation: styleInt(mustGet(disp, "Orientation")),
+        IndentLevel: styleInt(mustGet(disp, "IndentLevel")),
+        MergeCells:  styleToggle(mustGet(disp, "MergeCells")),
+    }
+    return
+}
+
+//Color, or ColorIndex if it is none or automatic, so the style read is applied as it is.
+func readColor(obj Dispatcher) (string, int) {
+    index := styleInt(mustGet(obj, "ColorIndex"))
+    if index == XlColorIndexNone || index == XlColorIndexAutomatic {
+        return "", index
+    }
+    return colorString(mustGet(obj, "Color")), 0
+}
+
+//a mixed property of a range is null, which is read as "".
+func styleToggle(val interface{}) (Toggle) {
+    switch val {
+        case true:
+            return On
+        case false:
+            return Off
+    }
+    return Unchanged
+}
+
+//
+func styleInt(val interface{}) (int) {
+    n, _ := toInt(val)
+    return n
+}
+
+//
+func styleFloat(val interface{}) (float64) {
+    if f, ok := val.(float64); ok {
+        return f
+    }
+    return float64(styleInt(val))
+}
@@ -0,0 +1,149 @@
+package excel
+
+import (
+    "strings"
+    "testing"
+)
+
+//a Dispatcher which records the puts into it and into the objects got from it, by path.
+type putRecorder struct {
+    Dispatcher
+    path string
+    puts *[]string
+}
+
+//
+func (pr putRecorder) GetProperty(name string, params... interface{}) (interface{}, error) {
+    val, err := pr.Dispatcher.GetProperty(name, params...)
+    if disp, ok := val.(Dispatcher); ok {
+        return putRecorder{disp, pr.path+memberPath(name, params)+".", pr.puts}, err
+    }
+    return val, err
+}
+
+//
+func (pr putRecorder) PutProperty(name string, params... interface{}) (error) {
+    *pr.puts = append(*pr.puts, pr.path+name)
+    return pr.Dispatcher.PutProperty(name, params...)
+}
+
+//a range of a fake sheet, and the range which records the puts into it.
+func styleRange(t *testing.T) (*MSO, Range, Range, *[]string) {
+    mso, err := New(Option{"Backend": "fake"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    sheet, _ := mso.Sheet(1)
+    rg := sheet.Range("A1:B2")
+    puts := &[]string{}
+    return mso, rg, Range{putRecorder{rg.Dispatcher, "", puts}}, puts
+}
+
+func TestApplyStyleOrder(t *testing.T) {
+    mso, rg, recorded, puts := styleRange(t)
+    defer mso.Quit()
+    style := Style{
+        NumberFormat: "0.00",
+        Font:         Font{Name: "Arial", Bold: On, Color: "#FF0000"},
+        Interior:     Interior{Pattern: XlPatternGray8, Color: "#00FF00"},
+        Borders:      Borders{All: Border{LineStyle: XlContinuous}, Left: Border{Weight: XlThick}, InsideHorizontal: Border{LineStyle: XlDot}},
+        Alignment:    Alignment{Horizontal: XlHAlignCenter, WrapText: On, Orientation: -45},
+    }
+    if err := recorded.ApplyStyle(style); err != nil {
+        t.Fatal(err)
+    }
+    want := []string{"NumberFormat", "Font.Name", "Font.Bold", "Font.Color", "Interior.Pattern", "Interior.Color",
+        "Borders.LineStyle", "Borders(7).Weight", "Borders(12).LineStyle", "HorizontalAlignment", "WrapText", "Orientation"}
+    if strings.Join(*puts, ",") != strings.Join(want, ",") {
+        t.Fatalf("puts %v, want %v", *puts, want)
+    }
+    got, err := rg.ReadStyle()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got.NumberFormat != "0.00" || got.Font.Name != "Arial" || got.Font.Bold != On || got.Font.Color != "#FF0000" {
+        t.Fatalf("Font is %+v", got.Font)
+    }
+    if got.Interior.Pattern != XlPatternGray8 || got.Borders.Left.Weight != XlThick {
+        t.Fatalf("Interior is %+v, Left is %+v", got.Interior, got.Borders.Left)
+    }
+    if got.Alignment.Orientation != -45 || got.Alignment.Horizontal != XlHAlignCenter || got.Alignment.WrapText != On {
+        t.Fatalf("Alignment is %+v", got.Alignment)
+    }
+}
+
+func TestApplyStyleValidated(t *testing.T) {
+    mso, rg, recorded, puts := styleRange(t)
+    defer mso.Quit()
+    font := Font{Name: "Arial"}
+    tests := []Style{
+        {Font: font, Borders: Borders{InsideHorizontal: Border{Weight: 3}}},
+        {Font: font, Borders: Borders{DiagonalUp: Border{LineStyle: XlContinuous, Color: "red"}}},
+        {Font: font, Interior: Interior{Pattern: 3}},
+        {Font: font, Interior: Interior{Pattern: XlPatternSolid, ColorIndex: 57}},
+        {Font: font, Alignment: Alignment{Orientation: 91}},
+        {Font: font, Alignment: Alignment{Orientation: -91}},
+        {Font: font, Alignment: Alignment{Orientation: XlHAlignCenter}},
+        {Font: font, Alignment: Alignment{IndentLevel: 251}},
+        {NumberFormat: "0", Font: Font{Bold: 3}},
+    }
+    for _, style := range tests {
+        *puts = nil
+        if err := recorded.ApplyStyle(style); err == nil {
+            t.Fatalf("%+v is applied", style)
+        }
+        if len(*puts) != 0 {
+            t.Fatalf("%+v: %v are put before the check", style, *puts)
+        }
+    }
+    if got, _ := rg.ReadStyle(); got.Font.Name != "Calibri" || got.NumberFormat != "General" {
+        t.Fatalf("the style is changed by incorrect ones: %+v", got)
+    }
+    for _, orientation := range []int{-90, 90, XlOrientationUpward, XlOrientationDownward, XlOrientationVertical, XlOrientationHorizontal} {
+        if err := rg.ApplyStyle(Style{Alignment: Alignment{Orientation: orientation}}); err != nil {
+            t.Fatalf("Orientation %v: %v", orientation, err)
+        }
+        if got, _ := rg.ReadStyle(); got.Alignment.Orientation != orientation {
+            t.Fatalf("Orientation %v is read as %v", orientation, got.Alignment.Orientation)
+        }
+    }
+}
+
+func TestColor(t *testing.T) {
+    tests := []struct {
+        s   string
+        bgr int
+    }{
+        {"#FF0000", 0x0000FF},
+        {"#00FF00", 0x00FF00},
+        {"#0000FF", 0xFF0000},
+        {"#123456", 0x563412},
+        {"#FFFFFF", 0xFFFFFF},
+        {"#000000", 0},
+    }
+    for _, test := range tests {
+        bgr, err := colorValue(test.s)
+        if err != nil || bgr != test.bgr {
+            t.Errorf("colorValue(%q) is %06X, %v, want %06X", test.s, bgr, err, test.bgr)
+        }
+        if s := colorString(float64(bgr)); s != test.s {
+            t.Errorf("colorString(%06X) is %q, want %q", bgr, s, test.s)
+        }
+        if s := colorString(int32(bgr)); s != test.s {
+            t.Errorf("colorString(int32 %06X) is %q, want %q", bgr, s, test.s)
+        }
+    }
+    if s, _ := colorValue("#abcdef"); colorString(s) != "#ABCDEF" {
+        t.Errorf("lowercase #abcdef is %q", colorString(s))
+    }
+    for _, s := range []string{"", "#12345", "#1234567", "123456", "#GG0000", "#-12345"} {
+        if n, err := colorValue(s); err == nil {
+            t.Errorf("colorValue(%q) is %06X", s, n)
+        }
+    }
+    for _, val := range []interface{}{nil, "", -1, 0x1000000} {
+        if s := colorString(val); s != "" {
+            t.Errorf("colorString(%v) is %q", val, s)
+        }
+    }
+}
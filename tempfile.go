@@ -0,0 +1,167 @@
+package excel
+
+import (
+    "bytes"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+)
+
+//tempFiles is the temp directory of an MSO for workbooks read from readers and written to writers,
+//it is removed on Quit.
+type tempFiles struct {
+    mutex sync.Mutex
+    dir   string
+}
+
+//a new directory in the temp directory, which is created by the first call.
+func (temps *tempFiles) mkdir() (string, error) {
+    temps.mutex.Lock()
+    defer temps.mutex.Unlock()
+    if temps.dir == "" {
+        dir, err := os.MkdirTemp("", "excel-")
+        if err != nil {
+            return "", err
+        }
+        temps.dir = dir
+    }
+    return os.MkdirTemp(temps.dir, "")
+}
+
+//
+func (temps *tempFiles) remove() (err error) {
+    temps.mutex.Lock()
+    defer temps.mutex.Unlock()
+    if temps.dir != "" {
+        err = os.RemoveAll(temps.dir)
+        temps.dir = ""
+    }
+    return
+}
+
+//TempDir is the directory of the temp files of the MSO, "" if there is not any.
+func (mso *MSO) TempDir() (string) {
+    if mso.temps == nil {
+        return ""
+    }
+    mso.temps.mutex.Lock()
+    defer mso.temps.mutex.Unlock()
+    return mso.temps.dir
+}
+
+//a free file name in the temp directory, name is like "report.xlsx".
+func (mso *MSO) tempFile(name string) (string, error) {
+    if mso.temps == nil {
+        mso.temps = &tempFiles{}
+    }
+    dir, err := mso.temps.mkdir()
+    if err != nil {
+        return "", err
+    }
+    return filepath.Join(dir, name), nil
+}
+
+//remove the temp files after the workbooks are closed.
+func (mso *MSO) removeTemps() {
+    if mso.temps != nil {
+        mso.temps.remove()
+    }
+}
+
+//the base name of a workbook read from a reader, xlsx if it has no extension.
+func readerName(name string) (string, error) {
+    name = filepath.Base(name)
+    if name == "." || name == string(filepath.Separator) || strings.TrimSuffix(name, filepath.Ext(name)) == "" {
+        return "", fmt.Errorf("incorrect workbook name %q", name)
+    }
+    if filepath.Ext(name) == "" {
+        name += ".xlsx"
+    }
+    return name, nil
+}
+
+//OpenReader opens the workbook read from r, name like "upload.csv" gives the Name and the format of it.
+//the temp file of it is removed on Quit.
+func OpenReader(r io.Reader, name string, opt... Option) (mso *MSO, err error) {
+    defer Except("OpenReader", &err)
+    mso = Initialize(opt...)
+    mso.WorkBook, err = mso.OpenWorkBookReader(r, name, mso.openOptions()...)
+    return
+}
+
+//OpenBytes is OpenReader of data.
+func OpenBytes(data []byte, name string, opt... Option) (*MSO, error) {
+    return OpenReader(bytes.NewReader(data), name, opt...)
+}
+
+//OpenWorkBookReader is OpenWorkBook of the workbook read from r, name like "upload.csv" gives the Name and the format of it.
+func (mso *MSO) OpenWorkBookReader(r io.Reader, name string, opts... OpenOptions) (wb WorkBook, err error) {
+    defer Except("OpenWorkBookReader", &err)
+    if name, err = readerName(name); err != nil {
+        return
+    }
+    full, err := mso.tempFile(name)
+    if err != nil {
+        return
+    }
+    if err = writeFile(full, r); err != nil {
+        return
+    }
+    if wb, err = mso.OpenWorkBook(full, opts...); err != nil {
+        os.Remove(full)
+    }
+    return
+}
+
+//
+func writeFile(full string, r io.Reader) (err error) {
+    f, err := os.Create(full)
+    if err != nil {
+        return
+    }
+    defer func() {
+        if e := f.Close(); err == nil {
+            err = e
+        }
+    }()
+    _, err = io.Copy(f, r)
+    return
+}
+
+//WriteTo writes the workbook in its format to w, as io.WriterTo.
+func (wb WorkBook) WriteTo(w io.Writer) (n int64, err error) {
+    return wb.WriteAs(w, nil)
+}
+
+//WriteAs writes the workbook to w in format, which is as args[1] of SaveAs, nil for the format of the workbook.
+//a copy of the workbook is saved as a temp file, which is removed after written.
+func (wb WorkBook) WriteAs(w io.Writer, format interface{}) (n int64, err error) {
+    defer Except("WorkBook.WriteAs", &err)
+    name := wb.Name()
+    if format == nil {
+        format = mustGet(wb.Dispatcher, "FileFormat")
+    }
+    full, err := wb.MSO.tempFile(strings.TrimSuffix(name, filepath.Ext(name)))
+    if err != nil {
+        return
+    }
+    args, err := saveAsArgs([]interface{}{full, format}, wb.FILEFORMAT, wb.Version)
+    if err != nil {
+        return
+    }
+    full = args[0].(string)
+    defer os.Remove(full)
+    ff, _ := toInt(args[1])
+    if err = wb.saveCopy(full, FileFormat(ff), nil); err != nil {
+        return
+    }
+    f, err := os.Open(full)
+    if err != nil {
+        return
+    }
+    defer f.Close()
+    return io.Copy(w, f)
+}
@@ -0,0 +1,128 @@
+package excel
+
+import (
+    "bytes"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+//the files under dir.
+func filesUnder(t *testing.T, dir string) (files []string) {
+    err := filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) (error) {
+        if err == nil && ! entry.IsDir() {
+            files = append(files, path)
+        }
+        return err
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+    return
+}
+
+//a writer which records the temp files of mso when it is written.
+type tempWriter struct {
+    buf   bytes.Buffer
+    t     *testing.T
+    mso   *MSO
+    files []string
+}
+
+//
+func (w *tempWriter) Write(p []byte) (int, error) {
+    if w.files == nil {
+        w.files = filesUnder(w.t, w.mso.TempDir())
+    }
+    return w.buf.Write(p)
+}
+
+func TestTempFilesOfReaders(t *testing.T) {
+    mso, err := OpenBytes([]byte("a,1\nb,2\n"), "upload.csv", Option{"Backend": "xlsx"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer mso.Quit()
+    dir := mso.TempDir()
+    if dir == "" || ! strings.HasPrefix(dir, os.TempDir()) {
+        t.Fatalf("TempDir is %q", dir)
+    }
+    full, _ := GetProperty(mso.WorkBook, "FullName")
+    if files := filesUnder(t, dir); len(files) != 1 || files[0] != full || filepath.Base(files[0]) != "upload.csv" {
+        t.Fatalf("temp files %v of %v", files, full)
+    }
+    sheet, _ := mso.Sheet(1)
+    if val, _ := sheet.GetCell(2, 2); val != 2.0 {
+        t.Fatalf("B2 is %v", val)
+    }
+    w := &tempWriter{t: t, mso: mso}
+    if _, err = mso.WorkBook.WriteAs(w, "xlsx"); err != nil || ! bytes.HasPrefix(w.buf.Bytes(), []byte("PK")) {
+        t.Fatal(err)
+    }
+    xlsx := w.buf.Bytes()
+    if len(w.files) != 2 || ! strings.HasPrefix(w.files[0], dir) || ! strings.HasPrefix(w.files[1], dir) {
+        t.Fatalf("temp files %v while written", w.files)
+    }
+    if files := filesUnder(t, dir); len(files) != 1 {
+        t.Fatalf("temp files %v after written", files)
+    }
+    w = &tempWriter{t: t, mso: mso}
+    if _, err = mso.WorkBook.WriteTo(w); err != nil || w.buf.String() != "a,1\nb,2\n" {
+        t.Fatalf("%q, %v", w.buf.String(), err)
+    }
+    wb, err := mso.OpenWorkBookReader(bytes.NewReader(xlsx), "back")
+    if err != nil || wb.Name() != "back.xlsx" {
+        t.Fatal(err)
+    }
+    if _, err = mso.OpenWorkBookReader(strings.NewReader("junk"), "bad.xlsx"); err == nil {
+        t.Fatal("junk is opened")
+    }
+    if files := filesUnder(t, dir); len(files) != 2 {
+        t.Fatalf("temp files %v after a bad workbook", files)
+    }
+}
+
+func TestTempFilesRemovedOnQuit(t *testing.T) {
+    mso, err := OpenBytes([]byte("a,1\n"), "upload.csv", Option{"Backend": "xlsx"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    dir := mso.TempDir()
+    work := func() (err error) {
+        defer Except("work", &err)
+        sheet, _ := mso.Sheet(1)
+        sheet.MustCells(0, 0, "panics")
+        return
+    }
+    if err = work(); err == nil {
+        t.Fatal("the panic is not recovered as an error")
+    }
+    if err = mso.Quit(); err != nil {
+        t.Fatal(err)
+    }
+    if _, err = os.Stat(dir); ! os.IsNotExist(err) {
+        t.Fatalf("%v is left after Quit: %v", dir, err)
+    }
+    if mso.TempDir() != "" {
+        t.Fatalf("TempDir is %q after Quit", mso.TempDir())
+    }
+}
+
+func TestTempFilesRemovedOnPanic(t *testing.T) {
+    mso, err := OpenBytes([]byte("a,1\n"), "upload.csv", Option{"Backend": "xlsx"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    dir := mso.TempDir()
+    func() {
+        defer func() {
+            recover()
+        }()
+        defer mso.Quit()
+        panic("boom")
+    }()
+    if _, err = os.Stat(dir); ! os.IsNotExist(err) {
+        t.Fatalf("%v is left after Quit: %v", dir, err)
+    }
+}
@@ -0,0 +1,620 @@
+package excel
+
+import (
+    "os"
+    "io"
+    "bufio"
+    "sort"
+    "time"
+    "strconv"
+    "strings"
+    "path/filepath"
+    "archive/zip"
+    "encoding/xml"
+    "encoding/csv"
+    "fmt"
+    "math"
+)
+
+func init() {
+    RegisterBackend("xlsx", NewXlsxExcel)
+}
+
+//NewXlsxExcel returns an Excel.Application in pure go, which reads and writes Office Open XML workbooks.
+//SaveAs supports xlsx, csv and txt(tab-delimited), files are written to temp files and renamed.
+//only values, formulas and sheet names are persisted: styles such as Font, Interior, Borders and number formats
+//are kept in memory until Quit, and dates are saved with one fixed date-time format.
+func NewXlsxExcel() (Dispatcher, error) {
+    xl := newMemExcel()
+    xl.app.Name = "XlsxApplication"
+    xl.open, xl.save = readWorkBook, writeWorkBook
+    return xl.app, nil
+}
+
+//
+func readWorkBook(book *memBook, full string) (err error) {
+    switch strings.ToLower(filepath.Ext(full)) {
+        case ".csv":
+            err = readCsv(book, full, ',')
+        case ".txt":
+            comma := book.delimiter
+            if comma == 0 {
+                comma = '\t'
+            }
+            err = readCsv(book, full, comma)
+        default:
+            err = readXlsx(book, full)
+    }
+    return
+}
+
+//
+func writeWorkBook(book *memBook, full string, format interface{}) (err error) {
+    ff, ok := toInt(format)
+    if ! ok {
+        one, e := FormatOf(full, 0)
+        if e != nil {
+            return fmt.Errorf("xlsx backend: %w", e)
+        }
+        ff = int(one)
+    }
+    return atomicWrite(full, func(tmp string) (error) {
+        switch FileFormat(ff) {
+            case XlOpenXMLWorkbook:
+                return writeXlsx(book, tmp)
+            case XlCSV, XlCSVWindows, XlCSVMSDOS:
+                return writeCsv(book, tmp, ',', false)
+            case XlCSVUTF8:
+                return writeCsv(book, tmp, ',', true)
+            case XlCurrentPlatformText, XlTextWindows, XlTextMSDOS:
+                return writeCsv(book, tmp, '\t', false)
+        }
+        return fmt.Errorf("xlsx backend: unsupported file format %+v of %v", format, full)
+    }, nil)
+}
+
+//the active sheet is read or written as csv, as Excel does.
+func readCsv(book *memBook, full string, comma rune) (err error) {
+    f, err := os.Open(full)
+    if err != nil {
+        return
+    }
+    defer f.Close()
+    br := bufio.NewReader(f)
+    if bom, _, e := br.ReadRune(); e == nil && bom != '\ufeff' {
+        br.UnreadRune()
+    }
+    r := csv.NewReader(br)
+    r.Comma, r.FieldsPerRecord, r.LazyQuotes = comma, -1, true
+    records, err := r.ReadAll()
+    if err != nil {
+        return
+    }
+    name := strings.TrimSuffix(filepath.Base(full), filepath.Ext(full))
+    sheet := book.newSheet(name)
+    for i, record := range records {
+        for j, field := range record {
+            if num, ok := parseNumber(field); ok {
+                sheet.put(i+1, j+1, num)
+            } else {
+                sheet.put(i+1, j+1, field)
+            }
+        }
+    }
+    book.sheets, book.active, book.nsheet = []*memSheet{sheet}, sheet, 1
+    return
+}
+
+//a number as Excel reads it, decimal digits with a sign, a point and an exponent,
+//not NaN, Inf, "1_000" or "0x1p4" which strconv.ParseFloat reads too.
+func parseNumber(s string) (float64, bool) {
+    i := 0
+    skip := func(set string) {
+        if i < len(s) && strings.IndexByte(set, s[i]) >= 0 {
+            i ++
+        }
+    }
+    skipDigits := func() (n int) {
+        for ; i < len(s) && s[i] >= '0' && s[i] <= '9'; i ++ {
+            n ++
+        }
+        return
+    }
+    skip("+-")
+    digits := skipDigits()
+    if i < len(s) && s[i] == '.' {
+        i ++
+        digits += skipDigits()
+    }
+    if digits == 0 {
+        return 0, false
+    }
+    if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+        i ++
+        skip("+-")
+        if skipDigits() == 0 {
+            return 0, false
+        }
+    }
+    if i != len(s) {
+        return 0, false
+    }
+    num, err := strconv.ParseFloat(s, 64)
+    return num, err == nil
+}
+
+//
+func writeCsv(book *memBook, full string, comma rune, bom bool) (err error) {
+    f, err := os.Create(full)
+    if err != nil {
+        return
+    }
+    defer func() {
+        if e := f.Close(); err == nil {
+            err = e
+        }
+    }()
+    if bom {
+        if _, err = f.WriteString("\ufeff"); err != nil {
+            return
+        }
+    }
+    w := csv.NewWriter(f)
+    w.Comma = comma
+    if sheet := book.active; sheet != nil && len(sheet.cells) > 0 {
+        r2, c2 := 1, 1
+        for rc := range sheet.cells {
+            if rc[0] > r2 {
+                r2 = rc[0]
+            }
+            if rc[1] > c2 {
+                c2 = rc[1]
+            }
+        }
+        for r := 1; r <= r2; r ++ {
+            record := make([]string, c2)
+            for c := 1; c <= c2; c ++ {
+                record[c-1] = String(sheet.get(r, c))
+            }
+            if err = w.Write(record); err != nil {
+                return
+            }
+        }
+    }
+    w.Flush()
+    return w.Error()
+}
+
+type xlsxRelationships struct {
+    Relationships []struct {
+        Id     string `xml:"Id,attr"`
+        Target string `xml:"Target,attr"`
+    } `xml:"Relationship"`
+}
+
+type xlsxWorkbook struct {
+    WorkbookPr struct {
+        Date1904 bool `xml:"date1904,attr"`
+    } `xml:"workbookPr"`
+    Sheets []struct {
+        Name string `xml:"name,attr"`
+        Rid  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+    } `xml:"sheets>sheet"`
+}
+
+type xlsxText struct {
+    T string `xml:"t"`
+    R []struct {
+        T string `xml:"t"`
+    } `xml:"r"`
+}
+
+type xlsxSharedStrings struct {
+    Si []xlsxText `xml:"si"`
+}
+
+type xlsxStyleSheet struct {
+    NumFmts []struct {
+        Id   int    `xml:"numFmtId,attr"`
+        Code string `xml:"formatCode,attr"`
+    } `xml:"numFmts>numFmt"`
+    CellXfs []struct {
+        NumFmtId int `xml:"numFmtId,attr"`
+    } `xml:"cellXfs>xf"`
+}
+
+type xlsxWorksheet struct {
+    Rows []struct {
+        R     int `xml:"r,attr"`
+        Cells []struct {
+            R  string   `xml:"r,attr"`
+            T  string   `xml:"t,attr"`
+            S  int      `xml:"s,attr"`
+            V  string   `xml:"v"`
+            Is xlsxText `xml:"is"`
+            F  struct {
+                Text string `xml:",chardata"`
+                T    string `xml:"t,attr"`
+                Ref  string `xml:"ref,attr"`
+                Si   string `xml:"si,attr"`
+            } `xml:"f"`
+        } `xml:"c"`
+    } `xml:"sheetData>row"`
+}
+
+//
+func (text xlsxText) String() (string) {
+    if len(text.R) == 0 {
+        return text.T
+    }
+    sl := []string{}
+    for _, r := range text.R {
+        sl = append(sl, r.T)
+    }
+    return strings.Join(sl, "")
+}
+
+//
+func readXml(files map[string]*zip.File, name string, v interface{}) (error) {
+    f, ok := files[name]
+    if ! ok {
+        return os.ErrNotExist
+    }
+    rc, err := f.Open()
+    if err != nil {
+        return err
+    }
+    defer rc.Close()
+    return xml.NewDecoder(rc).Decode(v)
+}
+
+//
+func readXlsx(book *memBook, full string) (err error) {
+    zr, err := zip.OpenReader(full)
+    if err != nil {
+        return
+    }
+    defer zr.Close()
+    files := map[string]*zip.File{}
+    for _, f := range zr.File {
+        files[strings.TrimPrefix(f.Name, "/")] = f
+    }
+
+    wb, rels, sst, styles := xlsxWorkbook{}, xlsxRelationships{}, xlsxSharedStrings{}, xlsxStyleSheet{}
+    if err = readXml(files, "xl/workbook.xml", &wb); err != nil {
+        return fmt.Errorf("%v: incorrect workbook: %v", full, err)
+    }
+    if err = readXml(files, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+        return fmt.Errorf("%v: incorrect workbook relationships: %v", full, err)
+    }
+    if err = readXml(files, "xl/sharedStrings.xml", &sst); err != nil && err != os.ErrNotExist {
+        return
+    }
+    if err = readXml(files, "xl/styles.xml", &styles); err != nil && err != os.ErrNotExist {
+        return
+    }
+    err = nil
+
+    dates := map[int]bool{}
+    for _, nf := range styles.NumFmts {
+        dates[nf.Id] = isDateFormat(nf.Code)
+    }
+    isdate := func(s int) (bool) {
+        if s < 0 || s >= len(styles.CellXfs) {
+            return false
+        }
+        id := styles.CellXfs[s].NumFmtId
+        if ret, ok := dates[id]; ok {
+            return ret
+        }
+        return (id >= 14 && id <= 22) || (id >= 45 && id <= 47)
+    }
+    targets := map[string]string{}
+    for _, rel := range rels.Relationships {
+        if strings.HasPrefix(rel.Target, "/") {
+            targets[rel.Id] = strings.TrimPrefix(rel.Target, "/")
+        } else {
+            targets[rel.Id] = "xl/"+rel.Target
+        }
+    }
+
+    sheets := []*memSheet{}
+    for _, one := range wb.Sheets {
+        ws := xlsxWorksheet{}
+        if err = readXml(files, targets[one.Rid], &ws); err != nil {
+            return fmt.Errorf("%v: incorrect sheet %v: %v", full, one.Name, err)
+        }
+        sheet := book.newSheet(one.Name)
+        shared := map[string]xlsxShared{}
+        r := 0
+        for _, row := range ws.Rows {
+            if r ++; row.R > 0 {
+                r = row.R
+            }
+            c := 0
+            for _, cell := range row.Cells {
+                if c ++; cell.R != "" {
+                    if ref, e := ParseCellRef(cell.R); e == nil {
+                        c = ref.Column
+                    }
+                }
+                var val interface{}
+                switch cell.T {
+                    case "s":
+                        if i, e := strconv.Atoi(cell.V); e == nil && i >= 0 && i < len(sst.Si) {
+                            val = sst.Si[i].String()
+                        }
+                    case "inlineStr":
+                        val = cell.Is.String()
+                    case "str", "e":
+                        val = cell.V
+                    case "b":
+                        val = cell.V == "1"
+                    default:
+                        if cell.V == "" {
+                            break
+                        }
+                        num, ok := parseNumber(cell.V)
+                        if ! ok {
+                            return fmt.Errorf("%v: incorrect number %v in %v!%v%v", full, cell.V, one.Name, ColumnItoa(c), r)
+                        }
+                        val = num
+                        if isdate(cell.S) {
+                            if t, e := FromExcelSerial(num, wb.WorkbookPr.Date1904, DateLocation); e == nil {
+                                val = t
+                            }
+                        }
+                }
+                sheet.put(r, c, val)
+                text := cell.F.Text
+                if cell.F.T == "shared" {
+                    if text, err = sharedFormula(shared, cell.F.Si, text, CellRef{Row: r, Column: c}); err != nil {
+                        return fmt.Errorf("%v: incorrect shared formula in %v!%v%v: %v", full, one.Name, ColumnItoa(c), r, err)
+                    }
+                }
+                if text != "" {
+                    sheet.loadFormula(r, c, text, cell.F.T, cell.F.Ref)
+                }
+            }
+        }
+        sheets = append(sheets, sheet)
+    }
+    if len(sheets) == 0 {
+        return fmt.Errorf("%v: workbook has no sheet", full)
+    }
+    book.sheets, book.active, book.nsheet = sheets, sheets[0], len(sheets)
+    book.disp.Set("Date1904", wb.WorkbookPr.Date1904)
+    return
+}
+
+//format code with date or time placeholders outside of quotes, brackets and escapes.
+func isDateFormat(code string) (bool) {
+    quoted, bracket := false, false
+    for i := 0; i < len(code); i ++ {
+        ch := code[i]
+        switch {
+            case quoted:
+                quoted = ch != '"'
+            case bracket:
+                bracket = ch != ']'
+            case ch == '"':
+                quoted = true
+            case ch == '[':
+                bracket = true
+            case ch == '\\' || ch == '_' || ch == '*':
+                i ++
+            case strings.IndexByte("dmyhsDMYHS", ch) != -1:
+                return true
+        }
+    }
+    return false
+}
+
+const (
+    xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/><Override PartName="/xl/sharedStrings.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sharedStrings+xml"/>%v</Types>`
+    xlsxSheetType = `<Override PartName="/xl/worksheets/sheet%v.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`
+    xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+    xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">%v<Relationship Id="rIdStyles" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/><Relationship Id="rIdStrings" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/sharedStrings" Target="sharedStrings.xml"/></Relationships>`
+    xlsxSheetRel = `<Relationship Id="rId%v" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%v.xml"/>`
+    xlsxWorkbookXml = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><workbookPr%v/><sheets>%v</sheets><calcPr fullCalcOnLoad="1"/></workbook>`
+    xlsxSheet = `<sheet name="%v" sheetId="%v" r:id="rId%v"/>`
+    xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts><fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills><borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders><cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs><cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="22" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs><cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles></styleSheet>`
+    xlsxWorksheetXml = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+)
+
+//the first cell of a shared formula, whose text is of the cell.
+type xlsxShared struct {
+    text string
+    cell CellRef
+}
+
+//the text of a shared formula at cell, an empty text is of the first cell with si, with its references moved to cell.
+func sharedFormula(shared map[string]xlsxShared, si string, text string, cell CellRef) (string, error) {
+    if text != "" {
+        if _, err := ParseFormula("="+text); err != nil {
+            return "", err
+        }
+        shared[si] = xlsxShared{text, cell}
+        return text, nil
+    }
+    first, ok := shared[si]
+    if ! ok {
+        return "", fmt.Errorf("formula %q is not found", si)
+    }
+    f, _ := ParseFormula("="+first.text)
+    f.Rebase(first.cell, cell)
+    return strings.TrimPrefix(f.String(), "="), nil
+}
+
+//a formula of the file, its cached value is kept as the value of the cell.
+func (sheet *memSheet) loadFormula(r, c int, text string, t string, ref string) {
+    f := memFormula{text: "="+text}
+    if t == "array" {
+        if rg, err := ParseRangeRef(ref); err == nil {
+            r1, c1, r2, c2 := rg.Bounds()
+            f.array = RangeRef{From: CellRef{r1, c1, true, true}, To: CellRef{r2, c2, true, true}}.String()
+        }
+    }
+    if _, ok := sheet.cells[[2]int{r, c}]; ! ok {
+        sheet.cells[[2]int{r, c}] = ""
+    }
+    sheet.formulas[[2]int{r, c}] = f
+}
+
+//the <f> of a cell in A1 notation, a formula which is not parsed is saved as its value.
+func (sheet *memSheet) saveFormula(rc [2]int) (string) {
+    f, ok := sheet.formulas[rc]
+    if ! ok {
+        return ""
+    }
+    a1, err := sheet.formula(rc[0], rc[1], false)
+    if err != nil {
+        return ""
+    }
+    text := escapeXml(strings.TrimPrefix(a1, "="))
+    if f.array != "" {
+        rg, err := ParseRangeRef(f.array)
+        if r1, c1, _, _ := rg.Bounds(); err != nil || r1 != rc[0] || c1 != rc[1] {
+            return ""
+        }
+        return fmt.Sprintf(`<f t="array" ref="%v">%v</f>`, strings.Replace(f.array, "$", "", -1), text)
+    }
+    return "<f>"+text+"</f>"
+}
+
+//
+func escapeXml(s string) (string) {
+    sb := &strings.Builder{}
+    xml.EscapeText(sb, []byte(s))
+    return sb.String()
+}
+
+//
+func writeXlsx(book *memBook, full string) (err error) {
+    f, err := os.Create(full)
+    if err != nil {
+        return
+    }
+    defer func() {
+        if e := f.Close(); err == nil {
+            err = e
+        }
+    }()
+    zw := zip.NewWriter(f)
+    write := func(name string, content string) {
+        if err == nil {
+            var w io.Writer
+            if w, err = zw.Create(name); err == nil {
+                _, err = io.WriteString(w, content)
+            }
+        }
+    }
+
+    types, rels, sheets := "", "", ""
+    strs, sst := map[string]int{}, []string{}
+    date1904, _ := book.disp.Prop("Date1904").(bool)
+    for i, sheet := range book.sheets {
+        n := i + 1
+        types += fmt.Sprintf(xlsxSheetType, n)
+        rels += fmt.Sprintf(xlsxSheetRel, n, n)
+        sheets += fmt.Sprintf(xlsxSheet, escapeXml(String(sheet.disp.Prop("Name"))), n, n)
+
+        keys := make([][2]int, 0, len(sheet.cells))
+        for rc := range sheet.cells {
+            keys = append(keys, rc)
+        }
+        sort.Slice(keys, func(i, j int) bool {
+            return keys[i][0] < keys[j][0] || (keys[i][0] == keys[j][0] && keys[i][1] < keys[j][1])
+        })
+        sb := &strings.Builder{}
+        sb.WriteString(xlsxWorksheetXml)
+        row := 0
+        for _, rc := range keys {
+            if rc[0] != row {
+                if row != 0 {
+                    sb.WriteString("</row>")
+                }
+                row = rc[0]
+                fmt.Fprintf(sb, `<row r="%v">`, row)
+            }
+            ref := ColumnItoa(rc[1])+strconv.Itoa(rc[0])
+            val, f := sheet.cells[rc], sheet.saveFormula(rc)
+            if t, ok := val.(time.Time); ok {
+                if serial, e := ToExcelSerial(t, date1904); e == nil {
+                    fmt.Fprintf(sb, `<c r="%v" s="1">%v<v>%v</v></c>`, ref, f, strconv.FormatFloat(serial, 'g', -1, 64))
+                    continue
+                }
+            }
+            if s, ok := val.(string); ok && f != "" {
+                if s == "" {
+                    fmt.Fprintf(sb, `<c r="%v">%v</c>`, ref, f)
+                } else {
+                    fmt.Fprintf(sb, `<c r="%v" t="str">%v<v>%v</v></c>`, ref, f, escapeXml(s))
+                }
+                continue
+            }
+            switch val := val.(type) {
+                case float64:
+                    if math.IsNaN(val) || math.IsInf(val, 0) {
+                        fmt.Fprintf(sb, `<c r="%v" t="e">%v<v>#NUM!</v></c>`, ref, f)
+                    } else {
+                        fmt.Fprintf(sb, `<c r="%v">%v<v>%v</v></c>`, ref, f, strconv.FormatFloat(val, 'g', -1, 64))
+                    }
+                case bool:
+                    b := 0
+                    if val {
+                        b = 1
+                    }
+                    fmt.Fprintf(sb, `<c r="%v" t="b">%v<v>%v</v></c>`, ref, f, b)
+                default:
+                    s := String(val)
+                    i, ok := strs[s]
+                    if ! ok {
+                        i = len(sst)
+                        strs[s] = i
+                        sst = append(sst, s)
+                    }
+                    fmt.Fprintf(sb, `<c r="%v" t="s"><v>%v</v></c>`, ref, i)
+            }
+        }
+        if row != 0 {
+            sb.WriteString("</row>")
+        }
+        sb.WriteString("</sheetData></worksheet>")
+        write(fmt.Sprintf("xl/worksheets/sheet%v.xml", n), sb.String())
+    }
+
+    sb := &strings.Builder{}
+    fmt.Fprintf(sb, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" count="%v" uniqueCount="%v">`, len(sst), len(sst))
+    for _, s := range sst {
+        if strings.TrimSpace(s) != s {
+            fmt.Fprintf(sb, `<si><t xml:space="preserve">%v</t></si>`, escapeXml(s))
+        } else {
+            fmt.Fprintf(sb, `<si><t>%v</t></si>`, escapeXml(s))
+        }
+    }
+    sb.WriteString("</sst>")
+
+    pr := ""
+    if date1904 {
+        pr = ` date1904="1"`
+    }
+    write("[Content_Types].xml", fmt.Sprintf(xlsxContentTypes, types))
+    write("_rels/.rels", xlsxRels)
+    write("xl/workbook.xml", fmt.Sprintf(xlsxWorkbookXml, pr, sheets))
+    write("xl/_rels/workbook.xml.rels", fmt.Sprintf(xlsxWorkbookRels, rels))
+    write("xl/styles.xml", xlsxStyles)
+    write("xl/sharedStrings.xml", sb.String())
+    if err != nil {
+        zw.Close()
+        return
+    }
+    return zw.Close()
+}
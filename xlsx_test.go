@@ -0,0 +1,181 @@
+package excel
+
+import (
+    "archive/zip"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+)
+
+//write an xlsx file of one sheet with the xml of sheetData.
+func writeTestXlsx(t *testing.T, sheetData string) (string) {
+    full := filepath.Join(t.TempDir(), "test.xlsx")
+    f, err := os.Create(full)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer f.Close()
+    zw := zip.NewWriter(f)
+    files := map[string]string {
+        "xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+        "xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`,
+        "xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`+sheetData+`</sheetData></worksheet>`,
+    }
+    for name, data := range files {
+        w, err := zw.Create(name)
+        if err != nil {
+            t.Fatal(err)
+        }
+        w.Write([]byte(data))
+    }
+    if err = zw.Close(); err != nil {
+        t.Fatal(err)
+    }
+    return full
+}
+
+func TestXlsxSharedFormula(t *testing.T) {
+    full := writeTestXlsx(t, `<row r="1"><c r="A1"><v>1</v></c></row>`+
+        `<row r="2"><c r="A2"><f t="shared" ref="A2:A4" si="0">A1+1</f><v>2</v></c><c r="B2"><f t="shared" ref="B2:C3" si="1">$A$1*A2</f><v>2</v></c></row>`+
+        `<row r="3"><c r="A3"><f t="shared" si="0"/><v>3</v></c><c r="B3"><f t="shared" si="1"/><v>3</v></c><c r="C3"><f t="shared" si="1"/><v>2</v></c></row>`+
+        `<row r="4"><c r="A4"><f t="shared" si="0"/><v>4</v></c></row>`)
+    mso, err := Open(full, Option{"Backend": "xlsx"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer mso.Quit()
+    sheet, _ := mso.Sheet(1)
+    tests := []struct {
+        cell    string
+        formula string
+        value   float64
+    }{
+        {"A2", "=A1+1", 2},
+        {"A3", "=A2+1", 3},
+        {"A4", "=A3+1", 4},
+        {"B2", "=$A$1*A2", 2},
+        {"B3", "=$A$1*A3", 3},
+        {"C3", "=$A$1*B3", 2},
+    }
+    for _, test := range tests {
+        ref, _ := ParseCellRef(test.cell)
+        cell := sheet.Cell(ref.Row, ref.Column)
+        formula, err := cell.Formula()
+        value, _ := cell.Get()
+        cell.Release()
+        if err != nil || formula != test.formula || value != test.value {
+            t.Errorf("%v is %q of %v, %v, want %q of %v", test.cell, formula, value, err, test.formula, test.value)
+        }
+    }
+}
+
+func TestXlsxSharedFormulaMissing(t *testing.T) {
+    full := writeTestXlsx(t, `<row r="1"><c r="A1"><f t="shared" si="3"/><v>1</v></c></row>`)
+    mso, err := Open(full, Option{"Backend": "xlsx"})
+    if err == nil {
+        mso.Quit()
+        t.Fatal("a shared formula without its first cell is opened")
+    }
+    if ! strings.Contains(err.Error(), "shared formula") {
+        t.Fatal(err)
+    }
+}
+
+func TestXlsxRoundTrip(t *testing.T) {
+    full := filepath.Join(t.TempDir(), "round.xlsx")
+    date := time.Date(2024, 2, 29, 13, 45, 30, 0, DateLocation)
+    mso, err := New(Option{"Backend": "xlsx"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    first, _ := mso.Sheet(1)
+    first.Name("first")
+    values := []interface{}{"text", " spaced & <escaped> ", 3.25, -1e-7, true, false, date}
+    for i, val := range values {
+        first.PutCell(i+1, 1, val)
+    }
+    second, err := mso.AddSheet("second")
+    if err != nil {
+        t.Fatal(err)
+    }
+    second.PutCell(2, 3, "second C2")
+    if err = mso.WorkBook.SaveAs(full); err != nil {
+        t.Fatal(err)
+    }
+    mso.Quit()
+
+    if mso, err = Open(full, Option{"Backend": "xlsx"}); err != nil {
+        t.Fatal(err)
+    }
+    defer mso.Quit()
+    sheets := mso.Sheets()
+    if len(sheets) != 2 || sheets[0].Name() != "second" || sheets[1].Name() != "first" {
+        t.Fatalf("%v sheets", len(sheets))
+    }
+    for i, want := range values {
+        val, err := sheets[1].GetCell(i+1, 1)
+        if t1, ok := want.(time.Time); ok {
+            if t2, ok := val.(time.Time); ! ok || ! t2.Equal(t1) {
+                t.Errorf("A%v is %#v, want %v", i+1, val, t1)
+            }
+        } else if err != nil || val != want {
+            t.Errorf("A%v is %#v, %v, want %#v", i+1, val, err, want)
+        }
+    }
+    if val, _ := sheets[0].GetCell(2, 3); val != "second C2" {
+        t.Fatalf("C2 of second is %#v", val)
+    }
+    if val, _ := sheets[0].GetCell(1, 1); val != "" {
+        t.Fatalf("A1 of second is %#v", val)
+    }
+}
+
+func TestXlsxCsvNumbers(t *testing.T) {
+    fields := []string{"1", "-2.5", "+.5", "1e3", "2.E-2", "007", "NaN", "Inf", "-inf", "1_000", "0x1p4", "0x10", "1e", ".", "-", "1.2.3", " 1", "1e400"}
+    want := []interface{}{1.0, -2.5, 0.5, 1000.0, 0.02, 7.0}
+    for _, field := range fields[len(want):] {
+        want = append(want, field)
+    }
+    full := filepath.Join(t.TempDir(), "n.csv")
+    if err := os.WriteFile(full, []byte(strings.Join(fields, ",")+"\n"), 0644); err != nil {
+        t.Fatal(err)
+    }
+    mso, err := Open(full, Option{"Backend": "xlsx"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer mso.Quit()
+    sheet, _ := mso.Sheet(1)
+    for j, w := range want {
+        if val, _ := sheet.GetCell(1, j+1); val != w {
+            t.Errorf("%q is read as %#v, want %#v", fields[j], val, w)
+        }
+    }
+    saved := filepath.Join(filepath.Dir(full), "n.xlsx")
+    if err = mso.WorkBook.SaveAs(saved); err != nil {
+        t.Fatal(err)
+    }
+    one, err := Open(saved, Option{"Backend": "xlsx"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer one.Quit()
+    sheet, _ = one.Sheet(1)
+    for j, w := range want {
+        if val, _ := sheet.GetCell(1, j+1); val != w {
+            t.Errorf("%q is saved as %#v, want %#v", fields[j], val, w)
+        }
+    }
+}
+
+func TestXlsxIncorrectNumber(t *testing.T) {
+    for _, v := range []string{"NaN", "Inf", "1_000", "0x1p4"} {
+        full := writeTestXlsx(t, `<row r="1"><c r="A1"><v>`+v+`</v></c></row>`)
+        if mso, err := Open(full, Option{"Backend": "xlsx"}); err == nil {
+            mso.Quit()
+            t.Errorf("<v>%v</v> is read", v)
+        }
+    }
+}